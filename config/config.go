@@ -16,14 +16,44 @@ import (
 //	github_tokens -> GITHUB_TOKENS (comma separated)
 //	github_proxy  -> GITHUB_PROXY (e.g. socks5://127.0.0.1:9050)
 //	listen        -> LISTEN (e.g. :8080)
+//	sync_interval -> SYNC_INTERVAL (e.g. 6h, "off" for manual only)
+//	git_providers -> GIT_PROVIDERS (e.g. gitlab=https://git.example.com,gitea=https://gitea.example.com)
+//	upload_max_mb -> UPLOAD_MAX_MB (per-file image upload limit, default 10)
+//	upload_gc     -> UPLOAD_GC (orphaned uploads cleanup interval, e.g. 24h, "off")
+//	storage       -> STORAGE (local|s3, where uploads are kept)
+//	s3_endpoint   -> S3_ENDPOINT (host[:port], e.g. minio:9000)
+//	s3_bucket     -> S3_BUCKET
+//	s3_region     -> S3_REGION
+//	s3_access_key -> S3_ACCESS_KEY
+//	s3_secret_key -> S3_SECRET_KEY
+//	s3_use_ssl    -> S3_USE_SSL ("false" for plain http, e.g. local MinIO)
+//	s3_prefix     -> S3_PREFIX (key prefix inside the bucket, default uploads/)
+//	s3_public_url -> S3_PUBLIC_URL (bucket/CDN URL; empty = served via /uploads/)
+//	site_url      -> SITE_URL (public base URL for feeds, e.g. https://example.com)
+//	markdown_policy -> MARKDOWN_POLICY (strict|default|relaxed, HTML allowed in markdown)
 type cfg struct {
-	DatabaseURL  string `json:"database_url"`
-	JWTSecret    string `json:"jwt_secret"`
-	Log          string `json:"log"`
-	LogLevel     string `json:"log_level"`
-	GitHubTokens string `json:"github_tokens"`
-	GitHubProxy  string `json:"github_proxy"`
-	Listen       string `json:"listen"`
+	DatabaseURL    string `json:"database_url"`
+	JWTSecret      string `json:"jwt_secret"`
+	Log            string `json:"log"`
+	LogLevel       string `json:"log_level"`
+	GitHubTokens   string `json:"github_tokens"`
+	GitHubProxy    string `json:"github_proxy"`
+	Listen         string `json:"listen"`
+	SyncInterval   string `json:"sync_interval"`
+	GitProviders   string `json:"git_providers"`
+	UploadMaxMB    string `json:"upload_max_mb"`
+	UploadGC       string `json:"upload_gc"`
+	Storage        string `json:"storage"`
+	S3Endpoint     string `json:"s3_endpoint"`
+	S3Bucket       string `json:"s3_bucket"`
+	S3Region       string `json:"s3_region"`
+	S3AccessKey    string `json:"s3_access_key"`
+	S3SecretKey    string `json:"s3_secret_key"`
+	S3UseSSL       string `json:"s3_use_ssl"`
+	S3Prefix       string `json:"s3_prefix"`
+	S3PublicURL    string `json:"s3_public_url"`
+	SiteURL        string `json:"site_url"`
+	MarkdownPolicy string `json:"markdown_policy"`
 }
 
 func setEnvIfNotEmpty(key, val string) {
@@ -52,4 +82,19 @@ func Load() {
 	setEnvIfNotEmpty("GITHUB_TOKENS", c.GitHubTokens)
 	setEnvIfNotEmpty("GITHUB_PROXY", c.GitHubProxy)
 	setEnvIfNotEmpty("LISTEN", c.Listen)
+	setEnvIfNotEmpty("SYNC_INTERVAL", c.SyncInterval)
+	setEnvIfNotEmpty("GIT_PROVIDERS", c.GitProviders)
+	setEnvIfNotEmpty("UPLOAD_MAX_MB", c.UploadMaxMB)
+	setEnvIfNotEmpty("UPLOAD_GC", c.UploadGC)
+	setEnvIfNotEmpty("STORAGE", c.Storage)
+	setEnvIfNotEmpty("S3_ENDPOINT", c.S3Endpoint)
+	setEnvIfNotEmpty("S3_BUCKET", c.S3Bucket)
+	setEnvIfNotEmpty("S3_REGION", c.S3Region)
+	setEnvIfNotEmpty("S3_ACCESS_KEY", c.S3AccessKey)
+	setEnvIfNotEmpty("S3_SECRET_KEY", c.S3SecretKey)
+	setEnvIfNotEmpty("S3_USE_SSL", c.S3UseSSL)
+	setEnvIfNotEmpty("S3_PREFIX", c.S3Prefix)
+	setEnvIfNotEmpty("S3_PUBLIC_URL", c.S3PublicURL)
+	setEnvIfNotEmpty("SITE_URL", c.SiteURL)
+	setEnvIfNotEmpty("MARKDOWN_POLICY", c.MarkdownPolicy)
 }
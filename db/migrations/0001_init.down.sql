@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS github_tokens;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS settings;
+DROP TABLE IF EXISTS post;
+DROP TABLE IF EXISTS users;
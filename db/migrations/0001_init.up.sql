@@ -0,0 +1,74 @@
+-- Базовая схема. Все операторы идемпотентны, чтобы базы, созданные
+-- старым InitPool, сходились к этой версии без ручного вмешательства.
+
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    username TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'user',
+    email TEXT
+);
+
+-- Старые базы могли быть созданы без email/role
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+UPDATE users SET role = 'user' WHERE role IS NULL;
+ALTER TABLE users ALTER COLUMN role SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_uq ON users (email) WHERE email IS NOT NULL AND email <> '';
+
+-- Таблица постов (контент) с владельцем (user_id)
+CREATE TABLE IF NOT EXISTS post (
+    id SERIAL PRIMARY KEY,
+    label TEXT NOT NULL,
+    text  TEXT NOT NULL,
+    user_id INT NULL
+);
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS user_id INT NULL;
+
+-- ADD CONSTRAINT IF NOT EXISTS в Postgres нет, проверяем каталог вручную
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_post_user') THEN
+        ALTER TABLE post ADD CONSTRAINT fk_post_user
+            FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+    END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS settings (
+    user_id INT UNIQUE NOT NULL,
+    home_bg_url TEXT,
+    link_github TEXT,
+    link_tg TEXT,
+    link_custom TEXT,
+    slug TEXT,
+    CONSTRAINT fk_settings_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+ALTER TABLE settings ADD COLUMN IF NOT EXISTS slug TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS settings_slug_uq ON settings (slug) WHERE slug IS NOT NULL;
+
+-- Таблица проектов (GitHub репозитории)
+CREATE TABLE IF NOT EXISTS projects (
+    id SERIAL PRIMARY KEY,
+    repo_name TEXT UNIQUE NOT NULL,
+    title TEXT,
+    description TEXT,
+    image_url TEXT,
+    github_url TEXT,
+    custom_url TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS github_tokens (
+    id SERIAL PRIMARY KEY,
+    token TEXT UNIQUE NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    last_used_at TIMESTAMP NULL,
+    fail_count INT NOT NULL DEFAULT 0
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS projects_user_repo_uq;
+ALTER TABLE projects ADD CONSTRAINT projects_repo_name_key UNIQUE (repo_name);
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS fk_projects_user;
+ALTER TABLE projects DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,30 @@
+-- Проекты принадлежат конкретному пользователю
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS user_id INT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_projects_user') THEN
+        ALTER TABLE projects ADD CONSTRAINT fk_projects_user
+            FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+    END IF;
+END
+$$;
+
+-- Старые глобальные проекты отдаём первому администратору, а если его
+-- нет — первому пользователю. Без владельца проект не виден ни в одном
+-- профиле, поэтому без пользователей вовсе миграция падает.
+UPDATE projects
+   SET user_id = (SELECT id FROM users ORDER BY (role = 'admin') DESC, id LIMIT 1)
+ WHERE user_id IS NULL;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM projects WHERE user_id IS NULL) THEN
+        RAISE EXCEPTION 'projects without owner: no users to assign them to; register a user (or delete the projects) and run migrations again';
+    END IF;
+END
+$$;
+
+-- repo_name уникален в пределах владельца, а не глобально
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_repo_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS projects_user_repo_uq ON projects (user_id, repo_name);
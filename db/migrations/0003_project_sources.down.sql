@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_sync_runs;
+DROP TABLE IF EXISTS project_sources;
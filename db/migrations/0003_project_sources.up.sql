@@ -0,0 +1,27 @@
+-- Сохранённые источники проектов (GitHub user или owner/repo) для фоновой синхронизации
+CREATE TABLE IF NOT EXISTS project_sources (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source TEXT NOT NULL,
+    -- NULL — интервал по умолчанию (SYNC_INTERVAL), 0 — только вручную
+    interval_minutes INT NULL,
+    last_sync_at TIMESTAMP NULL,
+    last_status TEXT NOT NULL DEFAULT 'never',
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, source)
+);
+
+-- История запусков синхронизации
+CREATE TABLE IF NOT EXISTS project_sync_runs (
+    id SERIAL PRIMARY KEY,
+    source_id INT NOT NULL REFERENCES project_sources(id) ON DELETE CASCADE,
+    trigger TEXT NOT NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP NULL,
+    status TEXT NOT NULL DEFAULT 'running',
+    error TEXT NOT NULL DEFAULT '',
+    repos_count INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS project_sync_runs_source_idx ON project_sync_runs (source_id, started_at DESC);
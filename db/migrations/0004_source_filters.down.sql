@@ -0,0 +1,3 @@
+ALTER TABLE project_sources DROP COLUMN IF EXISTS include_private;
+ALTER TABLE project_sources DROP COLUMN IF EXISTS include_archived;
+ALTER TABLE project_sources DROP COLUMN IF EXISTS include_forks;
@@ -0,0 +1,4 @@
+-- Фильтры списка репозиториев источника
+ALTER TABLE project_sources ADD COLUMN IF NOT EXISTS include_forks BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE project_sources ADD COLUMN IF NOT EXISTS include_archived BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE project_sources ADD COLUMN IF NOT EXISTS include_private BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,7 @@
+ALTER TABLE github_tokens DROP COLUMN IF EXISTS checked_at;
+ALTER TABLE github_tokens DROP COLUMN IF EXISTS last_error;
+ALTER TABLE github_tokens DROP COLUMN IF EXISTS last_status;
+ALTER TABLE github_tokens DROP COLUMN IF EXISTS cooldown_until;
+ALTER TABLE github_tokens DROP COLUMN IF EXISTS rate_reset_at;
+ALTER TABLE github_tokens DROP COLUMN IF EXISTS rate_remaining;
+ALTER TABLE github_tokens DROP COLUMN IF EXISTS rate_limit;
@@ -0,0 +1,12 @@
+-- Состояние лимитов по каждому токену
+ALTER TABLE github_tokens ADD COLUMN IF NOT EXISTS rate_limit INT NULL;
+ALTER TABLE github_tokens ADD COLUMN IF NOT EXISTS rate_remaining INT NULL;
+ALTER TABLE github_tokens ADD COLUMN IF NOT EXISTS rate_reset_at TIMESTAMP NULL;
+-- Пауза после вторичного лимита (Retry-After) или исчерпания квоты
+ALTER TABLE github_tokens ADD COLUMN IF NOT EXISTS cooldown_until TIMESTAMP NULL;
+ALTER TABLE github_tokens ADD COLUMN IF NOT EXISTS last_status INT NULL;
+ALTER TABLE github_tokens ADD COLUMN IF NOT EXISTS last_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE github_tokens ADD COLUMN IF NOT EXISTS checked_at TIMESTAMP NULL;
+
+-- Раньше токен выключался навсегда после первого 401; даём им второй шанс
+UPDATE github_tokens SET enabled = true, fail_count = 0 WHERE enabled = false;
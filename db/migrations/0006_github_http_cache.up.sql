@@ -0,0 +1,10 @@
+-- Кэш ответов GitHub API для условных запросов (If-None-Match / If-Modified-Since).
+-- Тело храним, чтобы на 304 пройти пагинацию и upsert как обычно.
+CREATE TABLE IF NOT EXISTS github_http_cache (
+    url TEXT PRIMARY KEY,
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    link TEXT NOT NULL DEFAULT '',
+    body BYTEA NOT NULL,
+    fetched_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
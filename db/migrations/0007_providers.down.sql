@@ -0,0 +1,13 @@
+ALTER TABLE IF EXISTS api_http_cache RENAME TO github_http_cache;
+
+ALTER TABLE github_tokens DROP COLUMN IF EXISTS provider;
+
+DROP INDEX IF EXISTS project_sources_user_provider_source_uq;
+DELETE FROM project_sources WHERE provider <> 'github.com';
+ALTER TABLE project_sources DROP COLUMN IF EXISTS provider;
+ALTER TABLE project_sources ADD CONSTRAINT project_sources_user_id_source_key UNIQUE (user_id, source);
+
+DROP INDEX IF EXISTS projects_user_provider_repo_uq;
+DELETE FROM projects WHERE provider <> 'github.com';
+ALTER TABLE projects DROP COLUMN IF EXISTS provider;
+CREATE UNIQUE INDEX IF NOT EXISTS projects_user_repo_uq ON projects (user_id, repo_name);
@@ -0,0 +1,14 @@
+-- Несколько хостингов кода: провайдер — хост веб-интерфейса (github.com, gitlab.example.com)
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS provider TEXT NOT NULL DEFAULT 'github.com';
+DROP INDEX IF EXISTS projects_user_repo_uq;
+CREATE UNIQUE INDEX IF NOT EXISTS projects_user_provider_repo_uq ON projects (user_id, provider, repo_name);
+
+ALTER TABLE project_sources ADD COLUMN IF NOT EXISTS provider TEXT NOT NULL DEFAULT 'github.com';
+ALTER TABLE project_sources DROP CONSTRAINT IF EXISTS project_sources_user_id_source_key;
+CREATE UNIQUE INDEX IF NOT EXISTS project_sources_user_provider_source_uq ON project_sources (user_id, provider, source);
+
+-- Пул токенов у каждого провайдера свой
+ALTER TABLE github_tokens ADD COLUMN IF NOT EXISTS provider TEXT NOT NULL DEFAULT 'github.com';
+
+-- Кэш условных запросов общий для всех API
+ALTER TABLE IF EXISTS github_http_cache RENAME TO api_http_cache;
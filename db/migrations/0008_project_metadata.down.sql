@@ -0,0 +1,10 @@
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS stars,
+    DROP COLUMN IF EXISTS forks,
+    DROP COLUMN IF EXISTS language,
+    DROP COLUMN IF EXISTS languages,
+    DROP COLUMN IF EXISTS topics,
+    DROP COLUMN IF EXISTS homepage,
+    DROP COLUMN IF EXISTS license,
+    DROP COLUMN IF EXISTS pushed_at,
+    DROP COLUMN IF EXISTS archived;
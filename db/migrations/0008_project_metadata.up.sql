@@ -0,0 +1,12 @@
+-- Метаданные репозитория для карточек и сортировки на публичной странице
+ALTER TABLE projects
+    ADD COLUMN IF NOT EXISTS stars     INT     NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS forks     INT     NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS language  TEXT    NOT NULL DEFAULT '',
+    -- доля каждого языка в процентах: {"Go": 91.5, "HTML": 8.5}
+    ADD COLUMN IF NOT EXISTS languages JSONB   NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS topics    TEXT[]  NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS homepage  TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS license   TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS pushed_at TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS archived  BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,10 @@
+-- README репозиториев: исходный текст и корни для относительных ссылок,
+-- в HTML превращается при показе страницы проекта
+CREATE TABLE IF NOT EXISTS project_readmes (
+    project_id INT PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    path       TEXT NOT NULL DEFAULT '',
+    content    TEXT NOT NULL DEFAULT '',
+    raw_root   TEXT NOT NULL DEFAULT '',
+    web_root   TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
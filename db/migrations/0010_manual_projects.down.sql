@@ -0,0 +1,4 @@
+DELETE FROM projects WHERE source = 'manual';
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_source_check;
+ALTER TABLE projects DROP COLUMN IF EXISTS links;
+ALTER TABLE projects DROP COLUMN IF EXISTS source;
@@ -0,0 +1,15 @@
+-- Происхождение проекта: import — из хостинга кода (обновляется синхронизацией),
+-- manual — заведён вручную в админке, синхронизация его не трогает.
+-- У ручных проектов provider пустой, repo_name — слаг для адреса страницы.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'import';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'projects_source_check') THEN
+        ALTER TABLE projects ADD CONSTRAINT projects_source_check CHECK (source IN ('import', 'manual'));
+    END IF;
+END
+$$;
+
+-- Дополнительные ссылки: [{"title": "App Store", "url": "https://..."}]
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS links JSONB NOT NULL DEFAULT '[]';
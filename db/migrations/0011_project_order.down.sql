@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS projects_user_position_idx;
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS pinned,
+    DROP COLUMN IF EXISTS group_name;
@@ -0,0 +1,14 @@
+-- Ручной порядок проектов, закрепление и группы (разделы публичной страницы)
+ALTER TABLE projects
+    ADD COLUMN IF NOT EXISTS position   INT     NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS pinned     BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS group_name TEXT    NOT NULL DEFAULT '';
+
+-- Начальный порядок — тот, что был виден раньше: свежие сверху
+UPDATE projects p
+   SET position = o.rn
+  FROM (SELECT id, row_number() OVER (PARTITION BY user_id ORDER BY updated_at DESC, id) AS rn
+          FROM projects) o
+ WHERE p.id = o.id;
+
+CREATE INDEX IF NOT EXISTS projects_user_position_idx ON projects (user_id, pinned DESC, position, id);
@@ -0,0 +1,22 @@
+-- Медиатека: загруженные пользователем картинки. Файлы адресуются хэшем
+-- содержимого, так что один и тот же url может быть у нескольких людей.
+CREATE TABLE IF NOT EXISTS media (
+    id         SERIAL PRIMARY KEY,
+    user_id    INT         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url        TEXT        NOT NULL,
+    size_bytes BIGINT      NOT NULL DEFAULT 0,
+    width      INT         NOT NULL DEFAULT 0,
+    height     INT         NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, url)
+);
+
+-- Уже загруженные картинки проектов и фонов попадают в медиатеку владельца
+-- (размеры неизвестны — показываются как «—»)
+INSERT INTO media (user_id, url)
+SELECT user_id, image_url FROM projects
+ WHERE user_id IS NOT NULL AND image_url ~ '/[0-9a-f]{32}\.(jpg|png)$'
+UNION
+SELECT user_id, home_bg_url FROM settings
+ WHERE home_bg_url ~ '/[0-9a-f]{32}\.(jpg|png)$'
+ON CONFLICT (user_id, url) DO NOTHING;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS post_scheduled_idx;
+DROP INDEX IF EXISTS post_user_status_published_idx;
+ALTER TABLE post DROP CONSTRAINT IF EXISTS post_status_check;
+ALTER TABLE post
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS published_at;
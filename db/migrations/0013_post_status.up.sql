@@ -0,0 +1,24 @@
+-- Состояния постов: черновик, опубликован, отложен (published_at в будущем), в архиве.
+-- Публичные страницы показывают только published.
+ALTER TABLE post
+    ADD COLUMN IF NOT EXISTS status       TEXT        NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'post_status_check') THEN
+        ALTER TABLE post ADD CONSTRAINT post_status_check
+            CHECK (status IN ('draft', 'published', 'scheduled', 'archived'));
+    END IF;
+END
+$$;
+
+-- Уже существующие посты были опубликованы сразу; порядок id сохраняем
+UPDATE post SET published_at = NOW() - make_interval(secs => (SELECT MAX(id) FROM post) - id)
+ WHERE published_at IS NULL AND status = 'published';
+
+CREATE INDEX IF NOT EXISTS post_user_status_published_idx ON post (user_id, status, published_at DESC);
+-- Для планировщика отложенных публикаций
+CREATE INDEX IF NOT EXISTS post_scheduled_idx ON post (published_at) WHERE status = 'scheduled';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS post_slug_redirects;
+DROP INDEX IF EXISTS post_user_slug_uq;
+ALTER TABLE post DROP COLUMN IF EXISTS slug;
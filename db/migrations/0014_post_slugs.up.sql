@@ -0,0 +1,15 @@
+-- Постоянные адреса постов: /{slug}/posts/{post-slug}. Слаг уникален
+-- среди постов автора; существующим постам его выдаёт приложение при
+-- старте (транслитерация заголовка, см. handlers.BackfillPostSlugs).
+ALTER TABLE post ADD COLUMN IF NOT EXISTS slug TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS post_user_slug_uq ON post (user_id, slug) WHERE slug <> '';
+
+-- Прежние слаги переименованных постов — для 301 на актуальный адрес
+CREATE TABLE IF NOT EXISTS post_slug_redirects (
+    user_id    INT         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    slug       TEXT        NOT NULL,
+    post_id    INT         NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, slug)
+);
@@ -0,0 +1,19 @@
+-- История правок постов: каждое сохранение — полная копия заголовка и текста
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id            SERIAL PRIMARY KEY,
+    post_id       INT         NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+    author_id     INT         NULL REFERENCES users(id) ON DELETE SET NULL,
+    label         TEXT        NOT NULL,
+    text          TEXT        NOT NULL,
+    -- restored_from — ревизия, из которой восстановлена эта
+    restored_from INT         NULL REFERENCES post_revisions(id) ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS post_revisions_post_idx ON post_revisions (post_id, id DESC);
+
+-- Текущее состояние существующих постов — их первая ревизия
+INSERT INTO post_revisions (post_id, author_id, label, text, created_at)
+SELECT p.id, p.user_id, p.label, p.text, p.updated_at
+  FROM post p
+ WHERE NOT EXISTS (SELECT 1 FROM post_revisions r WHERE r.post_id = p.id);
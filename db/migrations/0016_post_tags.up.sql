@@ -0,0 +1,17 @@
+-- Теги постов: у каждого автора свой набор, пост ↔ тег — многие ко многим.
+-- slug — транслитерированное имя для адресов (/{slug}?tag=…, /{slug}/tags/…).
+CREATE TABLE IF NOT EXISTS tags (
+    id      SERIAL PRIMARY KEY,
+    user_id INT  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name    TEXT NOT NULL,
+    slug    TEXT NOT NULL,
+    UNIQUE (user_id, slug)
+);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id INT NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+    tag_id  INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS post_tags_tag_idx ON post_tags (tag_id);
@@ -0,0 +1,3 @@
+-- Краткое описание поста для карточек и лент; пустое — превью строится
+-- из текста (до <!--more--> или первые строки без разметки)
+ALTER TABLE post ADD COLUMN IF NOT EXISTS summary TEXT NOT NULL DEFAULT '';
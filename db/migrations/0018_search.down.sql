@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS projects_search_idx;
+ALTER TABLE projects DROP COLUMN IF EXISTS search;
+DROP INDEX IF EXISTS post_search_idx;
+ALTER TABLE post DROP COLUMN IF EXISTS search;
@@ -0,0 +1,25 @@
+-- Полнотекстовый поиск по постам и проектам. Вектор строится сразу в двух
+-- конфигурациях (русской и английской), чтобы находились словоформы обоих
+-- языков; генерируемые столбцы Postgres пересчитывает при каждой записи.
+-- Длинные тексты индексируются по началу: tsvector больше 1 МБ Postgres не
+-- строит, и INSERT/UPDATE очень длинного поста иначе бы падал.
+ALTER TABLE post ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', label), 'A') ||
+    setweight(to_tsvector('english', label), 'A') ||
+    setweight(to_tsvector('russian', left(summary, 10000)), 'B') ||
+    setweight(to_tsvector('english', left(summary, 10000)), 'B') ||
+    setweight(to_tsvector('russian', left(text, 100000)), 'C') ||
+    setweight(to_tsvector('english', left(text, 100000)), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS post_search_idx ON post USING GIN (search);
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('simple', repo_name), 'A') ||
+    setweight(to_tsvector('russian', left(COALESCE(description, ''), 10000)), 'B') ||
+    setweight(to_tsvector('english', left(COALESCE(description, ''), 10000)), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS projects_search_idx ON projects USING GIN (search);
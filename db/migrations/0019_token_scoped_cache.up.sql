@@ -0,0 +1,3 @@
+-- Ответы /user/... раньше кэшировались по одному адресу и могли достаться
+-- другому токену; теперь такие ключи включают токен, старые записи убираем
+DELETE FROM api_http_cache WHERE url ~ '/user(/|\?|$)';
@@ -0,0 +1,2 @@
+-- Удалённые записи кэша восстанавливать не нужно: они перезапросятся
+SELECT 1;
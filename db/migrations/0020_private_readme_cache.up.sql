@@ -0,0 +1,8 @@
+-- README приватных репозиториев больше не кэшируется: ключ кэша — только
+-- адрес. Какие из сохранённых README были приватными, по ключу не понять,
+-- поэтому убираем все ответы с содержимым файлов — публичные перезапросятся
+DELETE FROM api_http_cache
+ WHERE url ~ '/readme(\?|$)'
+    OR url ~ '/contents/'
+    OR url ~ '/repository/files/'
+    OR url ~ '/repositories/[^/]+/[^/]+/src/';
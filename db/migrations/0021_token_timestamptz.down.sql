@@ -0,0 +1,4 @@
+ALTER TABLE github_tokens
+    ALTER COLUMN checked_at TYPE TIMESTAMP,
+    ALTER COLUMN cooldown_until TYPE TIMESTAMP,
+    ALTER COLUMN rate_reset_at TYPE TIMESTAMP;
@@ -0,0 +1,7 @@
+-- Сброс квоты и пауза токена — моменты времени, а не «часы на стене»:
+-- в TIMESTAMP без зоны сравнение с NOW() сдвигалось на пояс сессии.
+-- Старые значения записывались в поясе сессии, в нём их и читаем.
+ALTER TABLE github_tokens
+    ALTER COLUMN rate_reset_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN cooldown_until TYPE TIMESTAMPTZ,
+    ALTER COLUMN checked_at TYPE TIMESTAMPTZ;
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"Site/db"
+	"Site/export"
+	"Site/handlers"
+	"Site/uploads"
+)
+
+// runExport — подкоманда `export <slug> <каталог|файл.zip>`: статическая
+// копия профиля для обычного хостинга. Нужен SITE_URL: от него строятся
+// абсолютные ссылки лент.
+func runExport(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: export <slug> <dir | file.zip>")
+		return 2
+	}
+	slug, out := args[0], args[1]
+	base := strings.TrimSpace(os.Getenv("SITE_URL"))
+	if base == "" {
+		fmt.Fprintln(os.Stderr, "export: SITE_URL is required (absolute links in feeds and links back to the site)")
+		return 2
+	}
+
+	db.InitPool()
+	defer db.ClosePool()
+	if err := uploads.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "export: uploads: %v\n", err)
+		return 1
+	}
+	if err := handlers.InitProviders(); err != nil {
+		fmt.Fprintf(os.Stderr, "export: providers: %v\n", err)
+		return 1
+	}
+	if err := handlers.InitMarkdown(); err != nil {
+		fmt.Fprintf(os.Stderr, "export: markdown: %v\n", err)
+		return 1
+	}
+
+	opt := export.Options{BaseURL: base}
+	var (
+		stats export.Stats
+		err   error
+	)
+	if strings.HasSuffix(strings.ToLower(out), ".zip") {
+		f, ferr := os.Create(out)
+		if ferr != nil {
+			fmt.Fprintf(os.Stderr, "export: %v\n", ferr)
+			return 1
+		}
+		z := export.NewZip(f)
+		stats, err = export.Profile(context.Background(), newRouter(), slug, z, opt)
+		if err == nil {
+			err = z.Close()
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	} else {
+		stats, err = export.Profile(context.Background(), newRouter(), slug, export.Dir(out), opt)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	fmt.Printf("exported %s to %s: %d page(s), %d feed(s), %d upload(s), %d skipped\n",
+		slug, out, stats.Pages, stats.Feeds, stats.Uploads, stats.Skipped)
+	return 0
+}
@@ -0,0 +1,304 @@
+// Package export снимает статическую копию публичного профиля: обходит
+// страницы через обычный http.Handler сайта (те же шаблоны и данные, что
+// видит посетитель), переписывает ссылки на относительные и складывает
+// страницы, ленты и загруженные картинки в каталог или zip-архив.
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+
+	"Site/uploads"
+)
+
+// defaultMaxPages — предел страниц по умолчанию: защита от бесконечных
+// ссылок (курсоры пагинации, фильтры)
+const defaultMaxPages = 5000
+
+// Options — настройки выгрузки
+type Options struct {
+	// BaseURL — адрес живого сайта: с ним строятся абсолютные ссылки в
+	// лентах, и на него ведут ссылки за пределы профиля (вход, другие
+	// авторы). Пусто — такие ссылки остаются как есть.
+	BaseURL string
+	// MaxPages — предел числа страниц и лент; 0 — defaultMaxPages
+	MaxPages int
+}
+
+// Stats — что попало в выгрузку
+type Stats struct {
+	Pages   int
+	Feeds   int
+	Uploads int
+	Skipped int
+}
+
+// Profile выгружает профиль slug: главную, посты, теги, проекты и ленты.
+// Поиск в статике работать не может — его форма из страниц убирается.
+// Ошибка — если профиля нет или не удалось записать файл; страницы,
+// ответившие не 200, пропускаются и считаются в Stats.Skipped.
+func Profile(ctx context.Context, h http.Handler, slug string, sink Sink, opt Options) (Stats, error) {
+	if opt.MaxPages <= 0 {
+		opt.MaxPages = defaultMaxPages
+	}
+	c := &crawler{
+		ctx:   ctx,
+		h:     h,
+		sink:  sink,
+		opt:   opt,
+		root:  "/" + slug,
+		files: map[string]string{},
+		used:  map[string]bool{},
+	}
+	if opt.BaseURL != "" {
+		base, err := url.Parse(strings.TrimRight(opt.BaseURL, "/"))
+		if err != nil || base.Host == "" {
+			return Stats{}, fmt.Errorf("export: bad base URL %q", opt.BaseURL)
+		}
+		c.base = base
+	}
+
+	start := []string{"", "/projects", "/feed.xml", "/atom.xml", "/feed.json"}
+	for _, p := range start {
+		c.enqueue(&url.URL{Path: c.root + p})
+	}
+	for len(c.queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return c.stats, err
+		}
+		u := c.queue[0]
+		c.queue = c.queue[1:]
+		if err := c.fetch(u); err != nil {
+			return c.stats, err
+		}
+	}
+	return c.stats, nil
+}
+
+// crawler — обход профиля в ширину
+type crawler struct {
+	ctx  context.Context
+	h    http.Handler
+	sink Sink
+	opt  Options
+	base *url.URL
+	// root — путь профиля, "/slug"
+	root  string
+	queue []*url.URL
+	// files — адрес (путь?запрос) -> имя файла в выгрузке
+	files map[string]string
+	used  map[string]bool
+	stats Stats
+}
+
+// key — адрес без фрагмента, параметры в порядке сортировки
+func key(u *url.URL) string {
+	k := u.Path
+	if q := u.Query().Encode(); q != "" {
+		k += "?" + q
+	}
+	return k
+}
+
+// inProfile — страница профиля, которую можно выгрузить
+func (c *crawler) inProfile(p string) bool {
+	if p != c.root && !strings.HasPrefix(p, c.root+"/") {
+		return false
+	}
+	return p != c.root+"/search"
+}
+
+func isUpload(p string) bool {
+	return strings.HasPrefix(p, uploads.URLPrefix)
+}
+
+// enqueue ставит адрес в очередь (один раз) и возвращает имя его файла;
+// пустая строка — адрес не выгружается
+func (c *crawler) enqueue(u *url.URL) string {
+	// Адреса с "." и ".." не выгружаем: имя файла должно остаться внутри выгрузки
+	clean := path.Clean("/" + u.Path)
+	if clean != strings.TrimSuffix(u.Path, "/") {
+		return ""
+	}
+	u = &url.URL{Path: clean, RawQuery: u.Query().Encode()}
+	if !c.inProfile(u.Path) && !isUpload(u.Path) {
+		return ""
+	}
+	k := key(u)
+	if name, ok := c.files[k]; ok {
+		return name
+	}
+	if !isUpload(u.Path) && c.stats.Pages+c.stats.Feeds+len(c.queue) >= c.opt.MaxPages {
+		return ""
+	}
+	name := c.fileName(u)
+	c.files[k] = name
+	c.used[name] = true
+	c.queue = append(c.queue, u)
+	return name
+}
+
+// fileName — имя файла для адреса: "/slug" -> index.html,
+// "/slug/posts/p" -> posts/p/index.html, "/slug?tag=go" -> index-tag-go.html,
+// ленты и загрузки сохраняют имя
+func (c *crawler) fileName(u *url.URL) string {
+	if isUpload(u.Path) {
+		return "uploads/" + strings.TrimPrefix(u.Path, uploads.URLPrefix)
+	}
+	rel := strings.Trim(strings.TrimPrefix(u.Path, c.root), "/")
+	if ext := path.Ext(rel); (ext == ".xml" || ext == ".json") && u.RawQuery == "" {
+		return rel
+	}
+	base := "index"
+	if u.RawQuery != "" {
+		base += "-" + safeName(u.RawQuery)
+	}
+	name := path.Join(rel, base+".html")
+	for i := 2; c.used[name]; i++ {
+		name = path.Join(rel, fmt.Sprintf("%s-%d.html", base, i))
+	}
+	return name
+}
+
+// safeName — строка запроса в виде, пригодном для имени файла
+func safeName(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range s {
+		if r < 0x80 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	name := strings.Trim(b.String(), "-.")
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return name
+}
+
+// fetch запрашивает адрес у сайта и записывает ответ
+func (c *crawler) fetch(u *url.URL) error {
+	name := c.files[key(u)]
+	req := httptest.NewRequest(http.MethodGet, u.String(), nil).WithContext(c.ctx)
+	if c.base != nil {
+		req.Host = c.base.Host
+		req.Header.Set("X-Forwarded-Proto", c.base.Scheme)
+	}
+	rec := httptest.NewRecorder()
+	c.h.ServeHTTP(rec, req)
+	if rec.Code == http.StatusMovedPermanently || rec.Code == http.StatusFound {
+		// Старые адреса постов: на статике редирект — страница-указатель
+		if loc := rec.Header().Get("Location"); loc != "" && !isUpload(u.Path) {
+			to := c.link(loc, u, name)
+			c.stats.Pages++
+			return c.write(name, redirectPage(to))
+		}
+	}
+	if rec.Code != http.StatusOK {
+		if u.Path == c.root && u.RawQuery == "" {
+			return fmt.Errorf("export: profile %q: status %d", strings.TrimPrefix(c.root, "/"), rec.Code)
+		}
+		c.stats.Skipped++
+		return nil
+	}
+
+	body := rec.Body.Bytes()
+	ctype := rec.Header().Get("Content-Type")
+	switch {
+	case isUpload(u.Path):
+		c.stats.Uploads++
+	case strings.HasPrefix(ctype, "text/html"):
+		body = c.rewriteHTML(body, u, name)
+		c.stats.Pages++
+	default:
+		c.stats.Feeds++
+	}
+	return c.write(name, body)
+}
+
+func (c *crawler) write(name string, data []byte) error {
+	if err := c.sink.WriteFile(name, data); err != nil {
+		return fmt.Errorf("export: write %s: %w", name, err)
+	}
+	return nil
+}
+
+// redirectPage — страница, которая сразу уводит на to
+func redirectPage(to string) []byte {
+	esc := html.EscapeString(to)
+	return []byte(`<!DOCTYPE html><html><head><meta charset="utf-8">` +
+		`<meta http-equiv="refresh" content="0; url=` + esc + `">` +
+		`<link rel="canonical" href="` + esc + `"></head>` +
+		`<body><a href="` + esc + `">` + esc + `</a></body></html>`)
+}
+
+// link — новое значение ссылки raw со страницы page (файл from): адреса
+// профиля и загрузки становятся относительными путями к файлам выгрузки,
+// прочие адреса сайта — абсолютными на BaseURL, внешние не меняются
+func (c *crawler) link(raw string, page *url.URL, from string) string {
+	s := strings.TrimSpace(raw)
+	if s == "" || strings.HasPrefix(s, "#") {
+		return raw
+	}
+	ref, err := url.Parse(s)
+	if err != nil || ref.Opaque != "" {
+		return raw
+	}
+	if ref.Host != "" || ref.Scheme != "" {
+		if c.base == nil || !strings.EqualFold(ref.Host, c.base.Host) {
+			return raw
+		}
+	}
+	u := page.ResolveReference(ref)
+	name := c.enqueue(u)
+	if name == "" {
+		if c.base == nil || ref.Host != "" {
+			return raw
+		}
+		abs := *c.base
+		abs.Path, abs.RawQuery, abs.Fragment = u.Path, u.RawQuery, u.Fragment
+		return abs.String()
+	}
+	out := relPath(from, name)
+	if u.Fragment != "" {
+		out += "#" + u.EscapedFragment()
+	}
+	return out
+}
+
+// relPath — путь к файлу to относительно каталога файла from
+func relPath(from, to string) string {
+	dir := strings.Split(path.Dir(from), "/")
+	if dir[0] == "." {
+		dir = nil
+	}
+	target := strings.Split(to, "/")
+	i := 0
+	for i < len(dir) && i < len(target)-1 && dir[i] == target[i] {
+		i++
+	}
+	var b bytes.Buffer
+	for range dir[i:] {
+		b.WriteString("../")
+	}
+	for j, seg := range target[i:] {
+		if j > 0 {
+			b.WriteByte('/')
+		}
+		b.WriteString((&url.URL{Path: seg}).EscapedPath())
+	}
+	return b.String()
+}
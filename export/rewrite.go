@@ -0,0 +1,160 @@
+package export
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// cssURL — url(...) во встроенных стилях (фон главной страницы)
+var cssURL = regexp.MustCompile(`url\(\s*(['"]?)([^'")]*)(['"]?)\s*\)`)
+
+// linkAttrs — атрибуты с одним адресом
+var linkAttrs = map[string]bool{"href": true, "src": true, "action": true, "poster": true}
+
+// rewriteHTML переписывает ссылки страницы page (файл from) и убирает
+// формы поиска по профилю. Разметка, кроме изменённых тегов, остаётся
+// байт в байт.
+func (c *crawler) rewriteHTML(body []byte, page *url.URL, from string) []byte {
+	var out bytes.Buffer
+	z := html.NewTokenizer(bytes.NewReader(body))
+	// skip — глубина вложенных <form> внутри вырезаемой формы
+	skip := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+		if skip > 0 {
+			name, _ := z.TagName()
+			switch {
+			case tt == html.StartTagToken && string(name) == "form":
+				skip++
+			case tt == html.EndTagToken && string(name) == "form":
+				skip--
+			}
+			continue
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			out.Write(raw)
+			continue
+		}
+
+		tok := z.Token()
+		if tok.Data == "form" && c.isSearchForm(tok, page) {
+			if tt == html.StartTagToken {
+				skip = 1
+			}
+			continue
+		}
+		changed := false
+		for i, a := range tok.Attr {
+			if a.Namespace != "" {
+				continue
+			}
+			v := a.Val
+			switch {
+			case linkAttrs[a.Key]:
+				v = c.link(a.Val, page, from)
+			case a.Key == "srcset":
+				v = c.srcset(a.Val, page, from)
+			case a.Key == "style":
+				v = cssURL.ReplaceAllStringFunc(a.Val, func(m string) string {
+					sub := cssURL.FindStringSubmatch(m)
+					return "url(" + sub[1] + c.link(cssUnescape(sub[2]), page, from) + sub[3] + ")"
+				})
+			}
+			if v != a.Val {
+				tok.Attr[i].Val = v
+				changed = true
+			}
+		}
+		if changed {
+			out.WriteString(tok.String())
+		} else {
+			out.Write(raw)
+		}
+	}
+	return out.Bytes()
+}
+
+// isSearchForm — форма, отправляющая запрос на поиск по профилю
+func (c *crawler) isSearchForm(tok html.Token, page *url.URL) bool {
+	for _, a := range tok.Attr {
+		if a.Key != "action" {
+			continue
+		}
+		ref, err := url.Parse(strings.TrimSpace(a.Val))
+		if err != nil {
+			return false
+		}
+		return page.ResolveReference(ref).Path == c.root+"/search"
+	}
+	return false
+}
+
+// srcset — "адрес ширина, адрес ширина" с переписанными адресами
+func (c *crawler) srcset(v string, page *url.URL, from string) string {
+	parts := strings.Split(v, ",")
+	for i, p := range parts {
+		f := strings.Fields(p)
+		if len(f) == 0 {
+			continue
+		}
+		f[0] = c.link(f[0], page, from)
+		parts[i] = strings.Join(f, " ")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cssUnescape снимает CSS-экранирование, которое html/template ставит
+// в адресах внутри style ("\2f uploads\2f a.jpg")
+func cssUnescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		j := i + 1
+		for j < len(s) && j-i <= 6 && isHex(s[j]) {
+			j++
+		}
+		if j == i+1 {
+			b.WriteByte(s[j])
+			i = j
+			continue
+		}
+		var r rune
+		for _, h := range s[i+1 : j] {
+			r = r*16 + rune(hexVal(byte(h)))
+		}
+		b.WriteRune(r)
+		if j < len(s) && s[j] == ' ' {
+			j++
+		}
+		i = j - 1
+	}
+	return b.String()
+}
+
+func isHex(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F'
+}
+
+func hexVal(b byte) byte {
+	switch {
+	case b >= 'a':
+		return b - 'a' + 10
+	case b >= 'A':
+		return b - 'A' + 10
+	}
+	return b - '0'
+}
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Sink — куда складываются файлы выгрузки; name — путь через "/"
+type Sink interface {
+	WriteFile(name string, data []byte) error
+}
+
+// Dir — выгрузка в каталог на диске
+type Dir string
+
+func (d Dir) WriteFile(name string, data []byte) error {
+	p := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+// Zip — выгрузка в zip-архив; после выгрузки его нужно закрыть
+type Zip struct {
+	zw  *zip.Writer
+	now time.Time
+}
+
+// NewZip пишет архив в w
+func NewZip(w io.Writer) *Zip {
+	return &Zip{zw: zip.NewWriter(w), now: time.Now()}
+}
+
+func (z *Zip) WriteFile(name string, data []byte) error {
+	f, err := z.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: z.now})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// Close дописывает оглавление архива
+func (z *Zip) Close() error { return z.zw.Close() }
@@ -0,0 +1,141 @@
+// Package feed — ленты публикаций для подписки: RSS 2.0, Atom 1.0 и
+// JSON Feed 1.1.
+//
+// Обработчики собирают Feed из постов (абсолютные адреса, готовый HTML
+// содержимого), а пакет только сериализует его в нужный формат.
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Feed — лента
+type Feed struct {
+	Title       string
+	Description string
+	// Link — страница, которую лента повторяет; Self — адрес самой ленты
+	Link string
+	Self string
+	// Author — автор записей (в Atom и JSON Feed); пусто — Title
+	Author  string
+	Updated time.Time
+	Items   []Item
+}
+
+func (f Feed) author() string {
+	if f.Author != "" {
+		return f.Author
+	}
+	return f.Title
+}
+
+// updated — Updated, а у пустой ленты без даты — текущий момент
+// (в Atom дата обязательна)
+func (f Feed) updated() time.Time {
+	if f.Updated.IsZero() {
+		return time.Now()
+	}
+	return f.Updated
+}
+
+// Item — запись ленты
+type Item struct {
+	// ID — постоянный идентификатор (обычно постоянный адрес записи)
+	ID        string
+	Title     string
+	Link      string
+	Published time.Time
+	Updated   time.Time
+	// Summary — краткое описание без разметки
+	Summary string
+	// ContentHTML — полный HTML записи
+	ContentHTML string
+	Categories  []string
+}
+
+// RSSContentType — Content-Type для WriteRSS
+const RSSContentType = "application/rss+xml; charset=utf-8"
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Content string     `xml:"xmlns:content,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description,omitempty"`
+	Categories  []string `xml:"category"`
+	Content     cdata    `xml:"content:encoded"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+// WriteRSS пишет ленту в формате RSS 2.0; содержимое записей — в
+// content:encoded, так что читалки показывают пост целиком
+func WriteRSS(w io.Writer, f Feed) error {
+	doc := rss{
+		Version: "2.0",
+		Content: "http://purl.org/rss/1.0/modules/content/",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			AtomLink:    rssLink{Href: f.Self, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, it := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       it.Title,
+			Link:        it.Link,
+			GUID:        rssGUID{IsPermaLink: it.ID == it.Link, Value: it.ID},
+			PubDate:     it.Published.UTC().Format(time.RFC1123Z),
+			Description: it.Summary,
+			Categories:  it.Categories,
+			Content:     cdata{it.ContentHTML},
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
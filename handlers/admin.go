@@ -13,18 +13,29 @@ import (
 
 // AdminViewData — контекст для admin.html
 type AdminViewData struct {
-	ActiveTab    string
-	Posts        []models.Post
-	Edit         *models.Post
-	Projects     []models.Project
-	Settings     *models.Settings
-	IsAdmin      bool
-	CurrentLogin string
-	Error        string
+	ActiveTab string
+	Posts     []models.Post
+	// PostSearch и PostPager — поиск (?q=) и страницы списка постов
+	PostSearch string
+	PostPager  Pager
+	Edit       *models.Post
+	Projects   []models.Project
+	// EditProject — ручной проект в форме редактирования (?edit_project=)
+	EditProject *models.Project
+	Sources     []models.ProjectSource
+	SyncRuns    []models.SyncRun
+	Settings    *models.Settings
+	// Media — медиатека пользователя (вкладка и выбор картинки в редакторе поста)
+	Media   []models.Media
+	IsAdmin bool
+	// CanManageTokens — настоящая роль admin (пул токенов общий для всех)
+	CanManageTokens bool
+	CurrentLogin    string
+	Error           string
 }
 
 // AdminDashboard — единая точка входа в админку.
-// tab=projects → проекты, иначе → посты
+// tab=projects → проекты, tab=media → медиатека, иначе → посты
 func AdminDashboard(w http.ResponseWriter, r *http.Request) {
 	tab := r.URL.Query().Get("tab")
 	// Мы уже находимся за RequireAdmin — значит это админ
@@ -40,27 +51,34 @@ func AdminDashboard(w http.ResponseWriter, r *http.Request) {
 			"SELECT COALESCE(NULLIF(email,''), username, '') FROM users WHERE id=$1", uid,
 		).Scan(&login)
 	}
-	data := AdminViewData{ActiveTab: tab, IsAdmin: isAdmin, CurrentLogin: login}
+	data := AdminViewData{ActiveTab: tab, IsAdmin: isAdmin, CurrentLogin: login,
+		CanManageTokens: CurrentUserRole(r) == "admin"}
 
 	if tab == "projects" {
-		rows, err := db.Pool.Query(context.Background(), `
-    SELECT id, repo_name, title, description, image_url,
-           github_url, custom_url, enabled
-      FROM projects
-  ORDER BY updated_at DESC`)
-
+		prjs, err := loadUserProjects(r.Context(), uid, false)
 		if err != nil {
 			logger.Errorf("AdminDashboard projects: query error: %v", err)
 			http.Error(w, "DB error", http.StatusInternalServerError)
 			return
 		}
-		defer rows.Close()
-
-		for rows.Next() {
-			var p models.Project
-			rows.Scan(&p.ID, &p.RepoName, &p.Title, &p.Description,
-				&p.ImageURL, &p.GitHubURL, &p.CustomURL, &p.Enabled)
-			data.Projects = append(data.Projects, p)
+		data.Projects = prjs
+		data.EditProject = editManualProject(r, uid)
+		if data.Sources, err = loadUserProjectSources(r.Context(), uid); err != nil {
+			logger.Errorf("AdminDashboard projects: sources query error: %v", err)
+		}
+		if data.SyncRuns, err = loadUserSyncRuns(r.Context(), uid, 20); err != nil {
+			logger.Errorf("AdminDashboard projects: sync runs query error: %v", err)
+		}
+	} else if tab == "media" {
+		var err error
+		if data.Media, err = loadUserMedia(r.Context(), uid); err != nil {
+			logger.Errorf("AdminDashboard media: query error: %v", err)
+			http.Error(w, "DB error", http.StatusInternalServerError)
+			return
+		}
+		// проекты — для выбора, куда поставить картинку
+		if data.Projects, err = loadUserProjects(r.Context(), uid, false); err != nil {
+			logger.Errorf("AdminDashboard media: projects query error: %v", err)
 		}
 	} else if tab == "setting" {
 		// Вкладка Настройки — загружаем настройки текущего пользователя (админ тоже пользователь)
@@ -79,41 +97,51 @@ func AdminDashboard(w http.ResponseWriter, r *http.Request) {
 		// таб «Посты»
 		data.ActiveTab = "posts"
 		// Показываем посты текущего пользователя; для совместимости также подхватим старые глобальные (user_id IS NULL)
-		rows, err := db.Pool.Query(context.Background(),
-			"SELECT id,label,text FROM post WHERE user_id=$1 OR user_id IS NULL ORDER BY id DESC", uid)
+		posts, pager, err := loadAdminPosts(r.Context(), uid, r.URL.Query())
 		if err != nil {
-			// Фоллбек для старых схем БД без столбца user_id
-			if strings.Contains(err.Error(), "column \"user_id\" does not exist") ||
-				strings.Contains(strings.ToLower(err.Error()), "undefined column") {
-				rows, err = db.Pool.Query(context.Background(),
-					"SELECT id,label,text FROM post ORDER BY id DESC")
-			}
-			if err != nil {
-				logger.Errorf("AdminDashboard posts: query error: %v", err)
-				http.Error(w, "DB error", http.StatusInternalServerError)
-				return
-			}
+			logger.Errorf("AdminDashboard posts: query error: %v", err)
+			http.Error(w, "DB error", http.StatusInternalServerError)
+			return
+		}
+		data.PostSearch, data.PostPager = strings.TrimSpace(r.URL.Query().Get("q")), pager
+		if err := attachPostTags(r.Context(), posts); err != nil {
+			logger.Errorf("AdminDashboard posts: tags query error: %v", err)
 		}
-		defer rows.Close()
+		data.Posts = posts
+		// слаг профиля — для ссылок на страницы постов
+		data.Settings = userSettings(r.Context(), uid)
 
-		for rows.Next() {
-			var p models.Post
-			rows.Scan(&p.ID, &p.Label, &p.Text)
-			data.Posts = append(data.Posts, p)
+		if data.Media, err = loadUserMedia(r.Context(), uid); err != nil {
+			logger.Errorf("AdminDashboard posts: media query error: %v", err)
 		}
 		if editID := r.URL.Query().Get("edit_id"); editID != "" {
 			if id, err := strconv.Atoi(editID); err == nil {
-				var e models.Post
 				// Разрешаем редактировать свои и (для обратной совместимости) старые глобальные посты
-				db.Pool.QueryRow(context.Background(),
-					"SELECT id,label,text FROM post WHERE id=$1 AND (user_id=$2 OR user_id IS NULL)", id, uid).
-					Scan(&e.ID, &e.Label, &e.Text)
-				data.Edit = &e
+				e, _ := scanPost(db.Pool.QueryRow(context.Background(),
+					"SELECT "+postColumns+" FROM post WHERE id=$1 AND (user_id=$2 OR user_id IS NULL)", id, uid))
+				edit := []models.Post{e}
+				if err := attachPostTags(r.Context(), edit); err != nil {
+					logger.Errorf("AdminDashboard posts: edit tags query error: %v", err)
+				}
+				data.Edit = &edit[0]
 			}
 		}
 	}
 
-	tmpl := template.Must(template.ParseFiles("templates/admin/admin.html"))
+	tmpl := template.Must(template.ParseFiles("templates/admin/admin.html", "templates/admin/projects_table.html"))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	tmpl.ExecuteTemplate(w, "admin", data)
 }
+
+// userSettings — настройки пользователя; пустые (с UserID), если он их ещё не сохранял
+func userSettings(ctx context.Context, uid int) *models.Settings {
+	var s models.Settings
+	_ = db.Pool.QueryRow(ctx,
+		"SELECT user_id, COALESCE(home_bg_url,''), COALESCE(link_github,''), COALESCE(link_tg,''), COALESCE(link_custom,''), COALESCE(slug,'') FROM settings WHERE user_id=$1",
+		uid,
+	).Scan(&s.UserID, &s.HomeBgURL, &s.LinkGitHub, &s.LinkTG, &s.LinkCustom, &s.Slug)
+	if s.UserID == 0 {
+		s.UserID = uid
+	}
+	return &s
+}
@@ -133,6 +133,19 @@ func CurrentUserID(r *http.Request) (int, bool) {
 	return claims.UserID, true
 }
 
+// CurrentUserRole — роль из куки-JWT ("" если не залогинен)
+func CurrentUserRole(r *http.Request) string {
+	c, err := r.Cookie("session_token")
+	if err != nil || c.Value == "" {
+		return ""
+	}
+	claims, err := parseToken(c.Value)
+	if err != nil {
+		return ""
+	}
+	return claims.Role
+}
+
 // RegisterHandler — регистрация нового пользователя
 func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
@@ -4,7 +4,9 @@ import (
 	"Site/db"
 	"Site/logger"
 	"Site/models"
+	"Site/uploads"
 	"context"
+	"errors"
 	"html/template"
 	"net/http"
 	"regexp"
@@ -40,12 +42,13 @@ func CabinetPage(w http.ResponseWriter, r *http.Request) {
 	}
 	// Рендерим объединённый интерфейс (кабинет/админка) — вкладка «Настройки»
 	data := AdminViewData{
-		ActiveTab:    "setting",
-		Settings:     &s,
-		IsAdmin:      isAdmin,
-		CurrentLogin: login,
+		ActiveTab:       "setting",
+		Settings:        &s,
+		IsAdmin:         isAdmin,
+		CanManageTokens: CurrentUserRole(r) == "admin",
+		CurrentLogin:    login,
 	}
-	tmpl := template.Must(template.ParseFiles("templates/admin/admin.html"))
+	tmpl := template.Must(template.ParseFiles("templates/admin/admin.html", "templates/admin/projects_table.html"))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	tmpl.ExecuteTemplate(w, "admin", data)
 }
@@ -56,7 +59,8 @@ func CabinetSave(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/login", 303)
 		return
 	}
-	if err := r.ParseForm(); err != nil {
+	// Форма с файлом фона приходит как multipart, старая — urlencoded
+	if err := r.ParseMultipartForm(uploads.Default.MaxBytes); err != nil && !errors.Is(err, http.ErrNotMultipart) {
 		http.Error(w, "Bad request", 400)
 		return
 	}
@@ -92,6 +96,16 @@ func CabinetSave(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Загруженный файл фона важнее адреса из текстового поля
+	if imgURL, err := saveUploadedImage(r, "home_bg_file"); err != nil {
+		logger.Errorf("CabinetSave: upload background error (uid=%d): %v", uid, err)
+		msg, _ := uploadErrorText(err)
+		renderCabinetError(w, uid, msg)
+		return
+	} else if imgURL != "" {
+		bg = imgURL
+	}
+
 	_, err := db.Pool.Exec(context.Background(), `
 INSERT INTO settings(user_id, home_bg_url, link_github, link_tg, link_custom, slug)
 VALUES ($1,$2,$3,$4,$5,$6)
@@ -133,7 +147,7 @@ func renderCabinetError(w http.ResponseWriter, uid int, msg string) {
 		CurrentLogin: login,
 		Error:        msg,
 	}
-	tmpl := template.Must(template.ParseFiles("templates/admin/admin.html"))
+	tmpl := template.Must(template.ParseFiles("templates/admin/admin.html", "templates/admin/projects_table.html"))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	tmpl.ExecuteTemplate(w, "admin", data)
 }
@@ -0,0 +1,119 @@
+// handlers/export.go
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Site/export"
+	"Site/logger"
+)
+
+const (
+	// exportConcurrency — сколько выгрузок может идти одновременно на инстансе
+	exportConcurrency = 2
+	// exportCooldown — как часто один пользователь может запускать выгрузку
+	exportCooldown = 5 * time.Minute
+)
+
+// exportLimiter — выгрузка тяжёлая (обход всех страниц профиля внутри
+// запроса), поэтому их число ограничено, а пользователь не может
+// запускать их подряд
+type exportLimiter struct {
+	mu      sync.Mutex
+	running int
+	last    map[int]time.Time
+}
+
+var exports = &exportLimiter{last: map[int]time.Time{}}
+
+// acquire занимает место под выгрузку uid; ok=false — придётся подождать
+// (retry — сколько примерно)
+func (l *exportLimiter) acquire(uid int, now time.Time) (release func(), retry time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t, seen := l.last[uid]; seen && now.Sub(t) < exportCooldown {
+		return nil, exportCooldown - now.Sub(t), false
+	}
+	if l.running >= exportConcurrency {
+		return nil, 30 * time.Second, false
+	}
+	l.running++
+	l.last[uid] = now
+	for id, t := range l.last {
+		if now.Sub(t) >= exportCooldown {
+			delete(l.last, id)
+		}
+	}
+	return func() {
+		l.mu.Lock()
+		l.running--
+		l.mu.Unlock()
+	}, 0, true
+}
+
+// ExportProfile — zip-архив статической копии своего профиля для обычного
+// хостинга: POST /admin/export. site — роутер сайта, через который
+// выгрузка запрашивает страницы так же, как их видит посетитель.
+// Нужен SITE_URL; частота выгрузок ограничена, см. exportLimiter.
+func ExportProfile(site http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid, ok := CurrentUserID(r)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		// Абсолютные ссылки архива (ленты, выход на живой сайт) — только из
+		// настроек: заголовок Host присылает клиент
+		base := strings.TrimSpace(os.Getenv("SITE_URL"))
+		if base == "" {
+			http.Error(w, "Выгрузка недоступна: на сервере не задан SITE_URL", http.StatusServiceUnavailable)
+			return
+		}
+		s := userSettings(r.Context(), uid)
+		if s.Slug == "" {
+			http.Error(w, "Сначала задайте адрес страницы (slug) в настройках", http.StatusBadRequest)
+			return
+		}
+		release, retry, ok := exports.acquire(uid, time.Now())
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retry.Seconds())+1))
+			http.Error(w, "Выгрузка уже недавно запускалась или сервер занят другими — попробуйте через пару минут", http.StatusTooManyRequests)
+			return
+		}
+		defer release()
+
+		// Архив сначала собирается во временный файл: ошибку посреди выгрузки
+		// ещё можно показать, а не отдать обрезанный zip
+		tmp, err := os.CreateTemp("", "export-*.zip")
+		if err != nil {
+			logger.Errorf("ExportProfile: temp file error: %v", err)
+			http.Error(w, "Export error", http.StatusInternalServerError)
+			return
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+
+		z := export.NewZip(tmp)
+		stats, err := export.Profile(r.Context(), site, s.Slug, z, export.Options{BaseURL: base})
+		if err == nil {
+			err = z.Close()
+		}
+		if err != nil {
+			logger.Errorf("ExportProfile: export error (uid=%d): %v", uid, err)
+			http.Error(w, "Export error", http.StatusInternalServerError)
+			return
+		}
+		logger.Infof("ExportProfile: uid=%d slug=%s pages=%d feeds=%d uploads=%d skipped=%d",
+			uid, s.Slug, stats.Pages, stats.Feeds, stats.Uploads, stats.Skipped)
+
+		name := s.Slug + "-" + time.Now().Format("20060102") + ".zip"
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+		http.ServeContent(w, r, name, time.Now(), tmp)
+	}
+}
@@ -0,0 +1,214 @@
+// handlers/feeds.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"Site/feed"
+	"Site/logger"
+	"Site/markdown"
+	"Site/models"
+
+	"github.com/gorilla/mux"
+	"github.com/russross/blackfriday/v2"
+)
+
+// feedLimit — сколько последних постов попадает в ленту
+const feedLimit = 50
+
+// InitSiteURL проверяет SITE_URL при запуске. Без него абсолютные ссылки
+// лент берутся из заголовков Host и X-Forwarded-Proto, которые присылает
+// клиент: закэшированная прокси лента может получить ссылки на чужой хост.
+// Поэтому об отсутствии SITE_URL предупреждаем, а кривой адрес — ошибка.
+func InitSiteURL() error {
+	v := strings.TrimSpace(os.Getenv("SITE_URL"))
+	if v == "" {
+		logger.Errorf("SITE_URL is not set: absolute links in feeds are built from the request Host header; set SITE_URL in production")
+		return nil
+	}
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("SITE_URL %q: want http(s)://host", v)
+	}
+	return nil
+}
+
+// siteURL — адрес сайта без завершающего слэша для абсолютных ссылок в
+// лентах: SITE_URL, а без него — схема и хост запроса (с учётом прокси),
+// см. InitSiteURL
+func siteURL(r *http.Request) string {
+	if v := strings.TrimSpace(os.Getenv("SITE_URL")); v != "" {
+		return strings.TrimRight(v, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if p := r.Header.Get("X-Forwarded-Proto"); p == "http" || p == "https" {
+		scheme = p
+	}
+	return scheme + "://" + r.Host
+}
+
+// postFeedItems превращает опубликованные посты в записи ленты с полным HTML
+func postFeedItems(base, userSlug string, posts []models.Post) []feed.Item {
+	items := make([]feed.Item, 0, len(posts))
+	for _, p := range posts {
+		link := base + p.Path(userSlug)
+		it := feed.Item{
+			ID:          postFeedID(base, p),
+			Title:       p.Label,
+			Link:        link,
+			Updated:     p.UpdatedAt,
+			ContentHTML: string(markdown.Render(p.Text, mdPolicy, absoluteURLs(base))),
+		}
+		if p.PublishedAt != nil {
+			it.Published = *p.PublishedAt
+			// отложенный пост правят до выхода: updated не раньше публикации
+			if it.Published.After(it.Updated) {
+				it.Updated = it.Published
+			}
+		}
+		summary := p.Summary
+		if strings.TrimSpace(summary) == "" {
+			summary = p.Text
+		}
+		it.Summary, _ = markdown.Excerpt(summary, excerptRunes)
+		for _, t := range p.Tags {
+			it.Categories = append(it.Categories, t.Name)
+		}
+		items = append(items, it)
+	}
+	return items
+}
+
+// postFeedID — постоянный id записи ленты (tag URI, RFC 4151): номер поста
+// и дата создания не меняются, когда пост переименовывают и его адрес
+// переезжает, — читалки не покажут его заново
+func postFeedID(base string, p models.Post) string {
+	host := base
+	if u, err := url.Parse(base); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	return fmt.Sprintf("tag:%s,%s:post-%d", host, p.CreatedAt.Format("2006-01-02"), p.ID)
+}
+
+// absoluteURLs делает адреса от корня сайта ("/uploads/...") абсолютными:
+// читалки лент показывают запись вне сайта
+func absoluteURLs(base string) markdown.Transform {
+	return func(doc *blackfriday.Node) {
+		doc.Walk(func(n *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+			if entering && (n.Type == blackfriday.Link || n.Type == blackfriday.Image) {
+				if d := string(n.LinkData.Destination); strings.HasPrefix(d, "/") && !strings.HasPrefix(d, "//") {
+					n.LinkData.Destination = []byte(base + d)
+				}
+			}
+			return blackfriday.GoToNext
+		})
+	}
+}
+
+// feedUpdated — время самой свежей правки среди записей
+func feedUpdated(items []feed.Item) time.Time {
+	var t time.Time
+	for _, it := range items {
+		if it.Updated.After(t) {
+			t = it.Updated
+		}
+		if it.Published.After(t) {
+			t = it.Published
+		}
+	}
+	return t
+}
+
+// Форматы лент
+const (
+	feedRSS  = "rss"
+	feedAtom = "atom"
+	feedJSON = "json"
+)
+
+// writeFeed отдаёт ленту в нужном формате. Last-Modified — дата самой
+// свежей записи, повторный запрос без изменений получает 304.
+func writeFeed(w http.ResponseWriter, r *http.Request, f feed.Feed, format string) {
+	if !f.Updated.IsZero() {
+		mod := f.Updated.UTC().Truncate(time.Second)
+		if t, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !mod.After(t) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", mod.Format(http.TimeFormat))
+	}
+	var err error
+	switch format {
+	case feedAtom:
+		w.Header().Set("Content-Type", feed.AtomContentType)
+		err = feed.WriteAtom(w, f)
+	case feedJSON:
+		w.Header().Set("Content-Type", feed.JSONContentType)
+		err = feed.WriteJSON(w, f)
+	default:
+		w.Header().Set("Content-Type", feed.RSSContentType)
+		err = feed.WriteRSS(w, f)
+	}
+	if err != nil && r.Context().Err() == nil {
+		logger.Errorf("writeFeed %s: %v", f.Self, err)
+	}
+}
+
+// profileFeedPaths — адреса лент автора по форматам
+func profileFeedPaths(userSlug string) map[string]string {
+	base := "/" + userSlug
+	return map[string]string{
+		feedRSS:  base + "/feed.xml",
+		feedAtom: base + "/atom.xml",
+		feedJSON: base + "/feed.json",
+	}
+}
+
+// ProfileRSS — RSS последних постов автора: "/{slug}/feed.xml"
+func ProfileRSS(w http.ResponseWriter, r *http.Request) { serveProfileFeed(w, r, feedRSS) }
+
+// ProfileAtom — Atom последних постов автора: "/{slug}/atom.xml"
+func ProfileAtom(w http.ResponseWriter, r *http.Request) { serveProfileFeed(w, r, feedAtom) }
+
+// ProfileJSONFeed — JSON Feed последних постов автора: "/{slug}/feed.json"
+func ProfileJSONFeed(w http.ResponseWriter, r *http.Request) { serveProfileFeed(w, r, feedJSON) }
+
+func serveProfileFeed(w http.ResponseWriter, r *http.Request, format string) {
+	s, err := settingsBySlug(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	posts, err := queryPosts(r.Context(), `
+        SELECT `+postColumns+` FROM post
+         WHERE user_id = $1 AND status = 'published'
+         ORDER BY published_at DESC, id DESC
+         LIMIT $2`, s.UserID, feedLimit)
+	if err == nil {
+		err = attachPostTags(r.Context(), posts)
+	}
+	if err != nil {
+		logger.Errorf("serveProfileFeed: load posts error (uid=%d): %v", s.UserID, err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	base := siteURL(r)
+	items := postFeedItems(base, s.Slug, posts)
+	writeFeed(w, r, feed.Feed{
+		Title:       s.Slug,
+		Description: "Посты " + s.Slug,
+		Link:        base + "/" + s.Slug,
+		Self:        base + profileFeedPaths(s.Slug)[format],
+		Author:      s.Slug,
+		Updated:     feedUpdated(items),
+		Items:       items,
+	}, format)
+}
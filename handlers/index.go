@@ -2,39 +2,33 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"html/template"
 	"net/http"
 	"strings"
-	"unicode/utf8"
 
 	"Site/db"
 	"Site/logger"
 	"Site/models"
 	"github.com/gorilla/mux"
-	"github.com/russross/blackfriday/v2"
 )
 
 type IndexPageData struct {
 	Posts    []models.Post
 	Settings *models.Settings
+	// Tags — облако тегов автора, ActiveTag — фильтр ?tag=
+	Tags      []models.Tag
+	ActiveTag *models.Tag
+	Pager     Pager
 }
 
 func Index(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Pool.Query(context.Background(),
-		"SELECT id,label,text FROM post ORDER BY id DESC")
+	posts, pager, err := loadPublishedPage(r.Context(), r.URL.Query(), "TRUE")
 	if err != nil {
 		logger.Errorf("Index: load posts: %v", err)
 		http.Error(w, "DB error", 500)
 		return
 	}
-	defer rows.Close()
-
-	var posts []models.Post
-	for rows.Next() {
-		var p models.Post
-		rows.Scan(&p.ID, &p.Label, &p.Text)
-		posts = append(posts, p)
-	}
 
 	// Получаем настройки, если пользователь залогинен
 	var settings *models.Settings
@@ -51,15 +45,9 @@ func Index(w http.ResponseWriter, r *http.Request) {
 
 	tmpl := template.New("").Funcs(template.FuncMap{
 		"markdown": func(s string) template.HTML {
-			return template.HTML(blackfriday.Run([]byte(s)))
-		},
-		"truncate": func(s string, n int) string {
-			if utf8.RuneCountInString(s) <= n {
-				return s
-			}
-			rs := []rune(s)
-			return string(rs[:n]) + "..."
+			return renderMarkdown(s, nil)
 		},
+		"excerpt": postExcerpt,
 	})
 	tmpl = template.Must(tmpl.ParseFiles(
 		"templates/header.html",
@@ -67,7 +55,7 @@ func Index(w http.ResponseWriter, r *http.Request) {
 		"templates/footer.html",
 	))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	tmpl.ExecuteTemplate(w, "index", IndexPageData{Posts: posts, Settings: settings})
+	tmpl.ExecuteTemplate(w, "index", IndexPageData{Posts: posts, Settings: settings, Pager: pager})
 }
 
 // RootHandler — по требованию: "/" открывает логин/регистрацию, а для залогиненных — кабинет
@@ -87,6 +75,23 @@ func RootHandler(w http.ResponseWriter, r *http.Request) {
 	LoginHandler(w, r.WithContext(r.Context()))
 }
 
+// settingsBySlug ищет настройки пользователя по публичному слагу.
+// Любая ошибка (нет строки, старая схема без колонки) означает «не найдено».
+func settingsBySlug(ctx context.Context, slug string) (*models.Settings, error) {
+	var s models.Settings
+	err := db.Pool.QueryRow(ctx,
+		"SELECT user_id, COALESCE(home_bg_url,''), COALESCE(link_github,''), COALESCE(link_tg,''), COALESCE(link_custom,''), COALESCE(slug,'') FROM settings WHERE slug=$1",
+		slug,
+	).Scan(&s.UserID, &s.HomeBgURL, &s.LinkGitHub, &s.LinkTG, &s.LinkCustom, &s.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if s.UserID == 0 {
+		return nil, fmt.Errorf("settings for slug %q not found", slug)
+	}
+	return &s, nil
+}
+
 // PublicProfile — публичная страница пользователя по слагу: "/{slug}"
 func PublicProfile(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -99,39 +104,37 @@ func PublicProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var s models.Settings
-	err := db.Pool.QueryRow(context.Background(),
-		"SELECT user_id, COALESCE(home_bg_url,''), COALESCE(link_github,''), COALESCE(link_tg,''), COALESCE(link_custom,''), COALESCE(slug,'') FROM settings WHERE slug=$1",
-		slug,
-	).Scan(&s.UserID, &s.HomeBgURL, &s.LinkGitHub, &s.LinkTG, &s.LinkCustom, &s.Slug)
+	s, err := settingsBySlug(r.Context(), slug)
 	if err != nil {
-		// Совместимость со старыми БД: если нет колонки или таблицы — отдаём 404
-		e := strings.ToLower(err.Error())
-		if strings.Contains(e, "undefined column") ||
-			strings.Contains(e, "relation \"settings\" does not exist") ||
-			strings.Contains(e, "does not exist") {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Фильтр по тегу: /{slug}?tag=go
+	data := IndexPageData{Settings: s}
+	tagID := 0
+	if v := r.URL.Query().Get("tag"); v != "" {
+		t, err := tagBySlug(r.Context(), s.UserID, v)
+		if err != nil {
 			http.NotFound(w, r)
 			return
 		}
-	}
-	if err != nil || s.UserID == 0 {
-		http.NotFound(w, r)
-		return
+		data.ActiveTag, tagID = &t, t.ID
 	}
 
-	// Готовим список постов ТОЛЬКО данного пользователя
-	rows, err := db.Pool.Query(context.Background(),
-		"SELECT id,label,text FROM post WHERE user_id=$1 ORDER BY id DESC", s.UserID)
+	// Готовим страницу опубликованных постов ТОЛЬКО данного пользователя
+	posts, pager, err := loadPublishedPage(r.Context(), r.URL.Query(), `
+        user_id=$1 AND ($2 = 0 OR EXISTS (SELECT 1 FROM post_tags pt WHERE pt.post_id = post.id AND pt.tag_id = $2))`,
+		s.UserID, tagID)
 	if err != nil {
-		// Обратная совместимость: если в базе нет столбца user_id — берём все посты
+		// Обратная совместимость: если в базе нет столбца user_id — берём все опубликованные
 		if strings.Contains(strings.ToLower(err.Error()), "undefined column") {
-			rows, err = db.Pool.Query(context.Background(),
-				"SELECT id,label,text FROM post ORDER BY id DESC")
+			posts, pager, err = loadPublishedPage(r.Context(), r.URL.Query(), "TRUE")
 		}
 		// Если таблицы post нет вовсе — отдаём пустой список, а не 500
 		if err != nil && (strings.Contains(strings.ToLower(err.Error()), "relation \"post\" does not exist") ||
 			strings.Contains(strings.ToLower(err.Error()), "does not exist")) {
-			rows = nil
+			posts = nil
 			err = nil
 		}
 		if err != nil {
@@ -140,30 +143,20 @@ func PublicProfile(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	if rows != nil {
-		defer rows.Close()
-	}
+	data.Pager = pager
 
-	var posts []models.Post
-	if rows != nil {
-		for rows.Next() {
-			var p models.Post
-			rows.Scan(&p.ID, &p.Label, &p.Text)
-			posts = append(posts, p)
-		}
+	if err := attachPostTags(r.Context(), posts); err != nil {
+		logger.Errorf("PublicProfile: tags query failed: %v", err)
+	}
+	if data.Tags, err = loadPublishedTags(r.Context(), s.UserID); err != nil {
+		logger.Errorf("PublicProfile: tag cloud query failed: %v", err)
 	}
 
 	tmpl := template.New("").Funcs(template.FuncMap{
 		"markdown": func(s string) template.HTML {
-			return template.HTML(blackfriday.Run([]byte(s)))
-		},
-		"truncate": func(s string, n int) string {
-			if utf8.RuneCountInString(s) <= n {
-				return s
-			}
-			rs := []rune(s)
-			return string(rs[:n]) + "..."
+			return renderMarkdown(s, nil)
 		},
+		"excerpt": postExcerpt,
 	})
 	tmpl = template.Must(tmpl.ParseFiles(
 		"templates/header.html",
@@ -171,7 +164,7 @@ func PublicProfile(w http.ResponseWriter, r *http.Request) {
 		"templates/footer.html",
 	))
 	// Передаём посты и настройки пользователя
-	data := IndexPageData{Posts: posts, Settings: &s}
+	data.Posts = posts
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	tmpl.ExecuteTemplate(w, "index", data)
 }
@@ -0,0 +1,215 @@
+// handlers/manual_projects.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"Site/db"
+	"Site/logger"
+	"Site/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Ручные проекты — закрытые и клиентские работы без репозитория.
+// Хранятся в projects с source='manual' и пустым provider, так что
+// синхронизация (ON CONFLICT по user_id, provider, repo_name) до них не
+// дотягивается; repo_name служит слагом страницы проекта.
+
+// loadManualProject — ручной проект владельца по id
+func loadManualProject(ctx context.Context, uid, id int) (models.Project, error) {
+	return scanProject(db.Pool.QueryRow(ctx, `
+        SELECT `+projectColumns+` FROM projects WHERE id=$1 AND user_id=$2 AND source=$3`,
+		id, uid, models.ProjectManual))
+}
+
+// manualProjectSlug делает из заголовка слаг для адреса страницы
+func manualProjectSlug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		slug = "project"
+	}
+	return slug
+}
+
+// uniqueManualSlug добавляет к слагу -2, -3…, пока он занят у владельца
+func uniqueManualSlug(ctx context.Context, uid int, base string) (string, error) {
+	slug := base
+	for n := 2; ; n++ {
+		var exists bool
+		if err := db.Pool.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM projects WHERE user_id=$1 AND provider='' AND repo_name=$2)`,
+			uid, slug).Scan(&exists); err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// parseTags разбирает теги через запятую, без пустых и повторов
+func parseTags(raw string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, t := range strings.Split(raw, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[strings.ToLower(t)] {
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+// parseLinks разбирает ссылки по одной на строку: "Название | https://…"
+// или просто адрес (тогда название — хост); обратное — Project.LinksText
+func parseLinks(raw string) ([]models.ProjectLink, error) {
+	links := []models.ProjectLink{}
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		title, href, ok := strings.Cut(line, "|")
+		if !ok {
+			title, href = "", line
+		}
+		title, href = strings.TrimSpace(title), strings.TrimSpace(href)
+		u, err := url.Parse(href)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("неверная ссылка %q: нужен адрес http(s)://…", href)
+		}
+		if title == "" {
+			title = u.Host
+		}
+		links = append(links, models.ProjectLink{Title: title, URL: href})
+	}
+	return links, nil
+}
+
+// SaveManualProject создаёт (без id) или обновляет ручной проект
+func SaveManualProject(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	r.ParseMultipartForm(10 << 20)
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		http.Error(w, "Заголовок обязателен", http.StatusBadRequest)
+		return
+	}
+	links, err := parseLinks(r.FormValue("links"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p := models.Project{
+		Title:       title,
+		Description: strings.TrimSpace(r.FormValue("description")),
+		GitHubURL:   strings.TrimSpace(r.FormValue("repo_url")),
+		CustomURL:   strings.TrimSpace(r.FormValue("custom_url")),
+		Enabled:     r.FormValue("enabled") == "on",
+		Topics:      parseTags(r.FormValue("tags")),
+		Links:       links,
+	}
+	imgURL, err := saveUploadedImage(r, "image")
+	if err != nil {
+		logger.Errorf("SaveManualProject: upload image error (uid=%d): %v", uid, err)
+		msg, code := uploadErrorText(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	if id == 0 {
+		slug, err := uniqueManualSlug(r.Context(), uid, manualProjectSlug(title))
+		if err == nil {
+			_, err = db.Pool.Exec(r.Context(), `
+                INSERT INTO projects(user_id, provider, repo_name, source, title, description, image_url,
+                                     github_url, custom_url, enabled, topics, links, position)
+                VALUES($1,'',$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,
+                       (SELECT COALESCE(MAX(position), 0) + 1 FROM projects WHERE user_id = $1))`,
+				uid, slug, models.ProjectManual, p.Title, p.Description, imgURL,
+				p.GitHubURL, p.CustomURL, p.Enabled, p.Topics, p.Links)
+		}
+		if err != nil {
+			logger.Errorf("SaveManualProject: insert error (uid=%d): %v", uid, err)
+			http.Error(w, "DB error", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		// Пустой image_url в запросе — оставить прежнюю картинку
+		tag, err := db.Pool.Exec(r.Context(), `
+            UPDATE projects
+               SET title=$3, description=$4, image_url=COALESCE(NULLIF($5,''), image_url),
+                   github_url=$6, custom_url=$7, enabled=$8, topics=$9, links=$10, updated_at=NOW()
+             WHERE id=$1 AND user_id=$2 AND source='manual'`,
+			id, uid, p.Title, p.Description, imgURL, p.GitHubURL, p.CustomURL, p.Enabled, p.Topics, p.Links)
+		if err != nil {
+			logger.Errorf("SaveManualProject: update error (id=%d, uid=%d): %v", id, uid, err)
+			http.Error(w, "DB error", http.StatusInternalServerError)
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	http.Redirect(w, r, "/admin?tab=projects", 303)
+}
+
+// DeleteManualProject удаляет ручной проект (импортированные не удаляются:
+// их вернёт следующая синхронизация — для них есть выключатель)
+func DeleteManualProject(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	if _, err := db.Pool.Exec(r.Context(),
+		`DELETE FROM projects WHERE id=$1 AND user_id=$2 AND source='manual'`, id, uid); err != nil {
+		logger.Errorf("DeleteManualProject: delete error (id=%d, uid=%d): %v", id, uid, err)
+	}
+	http.Redirect(w, r, "/admin?tab=projects", 303)
+}
+
+// editManualProject — проект для формы редактирования из ?edit_project=
+func editManualProject(r *http.Request, uid int) *models.Project {
+	id, err := strconv.Atoi(r.URL.Query().Get("edit_project"))
+	if err != nil {
+		return nil
+	}
+	p, err := loadManualProject(r.Context(), uid, id)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logger.Errorf("AdminDashboard projects: load manual project %d error: %v", id, err)
+		}
+		return nil
+	}
+	return &p
+}
@@ -0,0 +1,131 @@
+// handlers/markdown.go
+package handlers
+
+import (
+	"Site/markdown"
+	"bytes"
+	"html/template"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+	"golang.org/x/net/html"
+)
+
+// mdPolicy — политика санитайзера для всего Markdown сайта (MARKDOWN_POLICY)
+var mdPolicy = markdown.Default()
+
+// InitMarkdown читает политику санитайзера из окружения
+func InitMarkdown() error {
+	p, err := markdown.FromEnv()
+	if err != nil {
+		return err
+	}
+	mdPolicy = p
+	return nil
+}
+
+// renderMarkdown — общий конвейер Markdown для постов и README: HTML
+// проходит санитайзер mdPolicy. Если задан rw, относительные ссылки и
+// картинки переписываются на хостинг репозитория.
+func renderMarkdown(src string, rw *urlRewriter) template.HTML {
+	if rw != nil {
+		return markdown.Render(src, mdPolicy, rw.walk)
+	}
+	return markdown.Render(src, mdPolicy)
+}
+
+// urlRewriter переписывает относительные адреса README: картинки — на
+// raw-хост, ссылки — в веб-интерфейс. Пути считаются от каталога README,
+// "/..." — от корня репозитория.
+type urlRewriter struct {
+	file    string // путь README в репозитории
+	rawRoot string
+	webRoot string
+}
+
+func (rw *urlRewriter) walk(doc *blackfriday.Node) {
+	doc.Walk(func(n *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if !entering {
+			return blackfriday.GoToNext
+		}
+		switch n.Type {
+		case blackfriday.Image:
+			n.LinkData.Destination = []byte(rw.resolve(rw.rawRoot, string(n.LinkData.Destination)))
+		case blackfriday.Link:
+			n.LinkData.Destination = []byte(rw.resolve(rw.webRoot, string(n.LinkData.Destination)))
+		case blackfriday.HTMLBlock, blackfriday.HTMLSpan:
+			// README часто центрируют логотип через <p align="center"><img src="...">
+			n.Literal = rw.rewriteHTML(n.Literal)
+		}
+		return blackfriday.GoToNext
+	})
+}
+
+// resolve приводит относительный адрес dest к root; абсолютные адреса,
+// якоря и ссылки с явной схемой (mailto:) не трогает
+func (rw *urlRewriter) resolve(root, dest string) string {
+	if root == "" || dest == "" || strings.HasPrefix(dest, "#") || strings.HasPrefix(dest, "//") {
+		return dest
+	}
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return dest
+	}
+	p := u.Path
+	if !strings.HasPrefix(p, "/") {
+		p = path.Join(path.Dir(rw.file), p)
+	}
+	// path.Clean от "/" не даёт выйти за корень репозитория через "../"
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	out := root + (&url.URL{Path: p}).EscapedPath()
+	if u.RawQuery != "" {
+		out += "?" + u.RawQuery
+	}
+	if u.Fragment != "" {
+		out += "#" + u.EscapedFragment()
+	}
+	return out
+}
+
+// rewriteHTML переписывает src/href во встроенном HTML
+func (rw *urlRewriter) rewriteHTML(raw []byte) []byte {
+	z := html.NewTokenizer(bytes.NewReader(raw))
+	var out bytes.Buffer
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				return raw
+			}
+			return out.Bytes()
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			out.Write(z.Raw())
+			continue
+		}
+		tok := z.Token()
+		changed := false
+		for i, a := range tok.Attr {
+			var v string
+			switch a.Key {
+			case "src":
+				v = rw.resolve(rw.rawRoot, a.Val)
+			case "href":
+				v = rw.resolve(rw.webRoot, a.Val)
+			default:
+				continue
+			}
+			if v != a.Val {
+				tok.Attr[i].Val, changed = v, true
+			}
+		}
+		if changed {
+			out.WriteString(tok.String())
+		} else {
+			out.Write(z.Raw())
+		}
+	}
+}
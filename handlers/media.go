@@ -0,0 +1,198 @@
+// handlers/media.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Site/db"
+	"Site/logger"
+	"Site/models"
+	"Site/uploads"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Медиатека — все картинки, загруженные пользователем: из формы медиатеки,
+// картинок проектов и фона профиля. Файлы общие (имя — хэш содержимого),
+// поэтому строка media — это «у меня есть этот файл», а сам файл удаляется,
+// только когда на него больше никто не ссылается.
+
+// recordMedia добавляет загруженную картинку в медиатеку пользователя
+func recordMedia(ctx context.Context, uid int, img *uploads.Image) error {
+	_, err := db.Pool.Exec(ctx, `
+        INSERT INTO media (user_id, url, size_bytes, width, height)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (user_id, url) DO UPDATE
+           SET size_bytes = EXCLUDED.size_bytes, width = EXCLUDED.width, height = EXCLUDED.height`,
+		uid, img.URL, img.Size, img.Width, img.Height)
+	return err
+}
+
+// loadUserMedia — медиатека пользователя со ссылками на каждую картинку, свежие сверху
+func loadUserMedia(ctx context.Context, uid int) ([]models.Media, error) {
+	rows, err := db.Pool.Query(ctx, `
+        SELECT m.id, m.user_id, m.url, m.size_bytes, m.width, m.height, m.created_at,
+               ARRAY(SELECT p.title FROM projects p
+                      WHERE p.user_id = m.user_id AND p.image_url = m.url ORDER BY p.title),
+               ARRAY(SELECT t.label FROM post t
+                      WHERE t.user_id = m.user_id AND strpos(t.text, m.url) > 0 ORDER BY t.id DESC),
+               EXISTS(SELECT 1 FROM settings s WHERE s.user_id = m.user_id AND s.home_bg_url = m.url)
+          FROM media m
+         WHERE m.user_id = $1
+         ORDER BY m.created_at DESC, m.id DESC`, uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Media
+	for rows.Next() {
+		var m models.Media
+		if err := rows.Scan(&m.ID, &m.UserID, &m.URL, &m.Size, &m.Width, &m.Height, &m.CreatedAt,
+			&m.Projects, &m.Posts, &m.HomeBg); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+// loadMediaItem — одна картинка из медиатеки владельца со ссылками
+func loadMediaItem(ctx context.Context, uid, id int) (models.Media, error) {
+	list, err := loadUserMedia(ctx, uid)
+	if err != nil {
+		return models.Media{}, err
+	}
+	for _, m := range list {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return models.Media{}, pgx.ErrNoRows
+}
+
+// MediaUpload принимает одну или несколько картинок в медиатеку
+func MediaUpload(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseMultipartForm(uploads.Default.MaxBytes); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	for _, fh := range r.MultipartForm.File["files"] {
+		file, err := fh.Open()
+		if err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		img, err := uploads.Default.Save(r.Context(), file)
+		file.Close()
+		if err == nil {
+			err = recordMedia(r.Context(), uid, img)
+		}
+		if err != nil {
+			logger.Errorf("MediaUpload: save error (uid=%d): %v", uid, err)
+			msg, code := uploadErrorText(err)
+			http.Error(w, msg, code)
+			return
+		}
+	}
+	http.Redirect(w, r, "/admin?tab=media", 303)
+}
+
+// MediaUse ставит картинку из медиатеки фоном профиля (target=home_bg)
+// или картинкой проекта (target=project, project_id)
+func MediaUse(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	var u string
+	if err := db.Pool.QueryRow(r.Context(),
+		`SELECT url FROM media WHERE id=$1 AND user_id=$2`, id, uid).Scan(&u); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logger.Errorf("MediaUse: load error (id=%d, uid=%d): %v", id, uid, err)
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	var err error
+	switch r.FormValue("target") {
+	case "home_bg":
+		_, err = db.Pool.Exec(r.Context(), `
+            INSERT INTO settings(user_id, home_bg_url) VALUES ($1, $2)
+            ON CONFLICT (user_id) DO UPDATE SET home_bg_url = EXCLUDED.home_bg_url`, uid, u)
+	case "project":
+		pid, _ := strconv.Atoi(r.FormValue("project_id"))
+		_, err = db.Pool.Exec(r.Context(), `
+            UPDATE projects SET image_url=$1, updated_at=NOW() WHERE id=$2 AND user_id=$3`, u, pid, uid)
+	default:
+		http.Error(w, "Unknown target", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Errorf("MediaUse: update error (id=%d, uid=%d): %v", id, uid, err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/admin?tab=media", 303)
+}
+
+// MediaDelete убирает неиспользуемую картинку из медиатеки; файл удаляется,
+// если он больше ничей
+func MediaDelete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	m, err := loadMediaItem(r.Context(), uid, id)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logger.Errorf("MediaDelete: load error (id=%d, uid=%d): %v", id, uid, err)
+		}
+		http.NotFound(w, r)
+		return
+	}
+	if m.InUse() {
+		http.Error(w, "Картинка используется — сначала уберите её из проектов, постов и фона", http.StatusConflict)
+		return
+	}
+	if _, err := db.Pool.Exec(r.Context(), `DELETE FROM media WHERE id=$1 AND user_id=$2`, id, uid); err != nil {
+		logger.Errorf("MediaDelete: delete error (id=%d, uid=%d): %v", id, uid, err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	if err := releaseUpload(r.Context(), m.URL); err != nil {
+		// не страшно: файл уберёт UploadGCWorker
+		logger.Errorf("MediaDelete: remove file %s error: %v", m.URL, err)
+	}
+	http.Redirect(w, r, "/admin?tab=media", 303)
+}
+
+// releaseUpload удаляет файл загрузки, если на него больше нет ссылок
+// (те же, что учитывает uploadReferences)
+func releaseUpload(ctx context.Context, u string) error {
+	var used bool
+	if err := db.Pool.QueryRow(ctx, `
+        SELECT EXISTS(SELECT 1 FROM media WHERE url = $1)
+            OR EXISTS(SELECT 1 FROM projects WHERE image_url = $1)
+            OR EXISTS(SELECT 1 FROM settings WHERE home_bg_url = $1)
+            OR EXISTS(SELECT 1 FROM post WHERE strpos(text, $1) > 0 OR strpos(summary, $1) > 0)
+            OR EXISTS(SELECT 1 FROM post_revisions WHERE strpos(text, $1) > 0)`, u).Scan(&used); err != nil {
+		return err
+	}
+	if used {
+		return nil
+	}
+	return uploads.Default.Delete(ctx, u)
+}
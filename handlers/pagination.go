@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"Site/db"
+	"Site/models"
+)
+
+const (
+	// publicPageSize — постов на странице публичного профиля
+	publicPageSize = 10
+	// adminPageSize — постов в списке админки
+	adminPageSize = 20
+)
+
+// Pager — ссылки на соседние страницы списка (строки запроса вида
+// "?tag=go&before=..."), пустая строка — соседней страницы нет
+type Pager struct {
+	Prev string
+	Next string
+	// Page и Pages — номер страницы и их число (только в админке,
+	// у публичных страниц курсоры вместо номеров)
+	Page  int
+	Pages int
+}
+
+// Any — есть ли куда листать
+func (p Pager) Any() bool { return p.Prev != "" || p.Next != "" }
+
+// postCursor — позиция в ленте, отсортированной по (published_at, id) DESC.
+// В адресе выглядит как "<микросекунды unix>.<id>".
+type postCursor struct {
+	At time.Time
+	ID int
+}
+
+func (c postCursor) String() string {
+	return fmt.Sprintf("%d.%d", c.At.UnixMicro(), c.ID)
+}
+
+func parsePostCursor(s string) (postCursor, bool) {
+	at, id, ok := strings.Cut(s, ".")
+	if !ok {
+		return postCursor{}, false
+	}
+	us, err1 := strconv.ParseInt(at, 10, 64)
+	n, err2 := strconv.Atoi(id)
+	if err1 != nil || err2 != nil || n <= 0 {
+		return postCursor{}, false
+	}
+	return postCursor{At: time.UnixMicro(us), ID: n}, true
+}
+
+func cursorOf(p models.Post) postCursor {
+	c := postCursor{ID: p.ID}
+	if p.PublishedAt != nil {
+		c.At = *p.PublishedAt
+	}
+	return c
+}
+
+// pageLink — текущий запрос с заменой параметров пагинации
+func pageLink(q url.Values, key, value string) string {
+	v := url.Values{}
+	for k, vs := range q {
+		switch k {
+		case "before", "after", "page":
+		default:
+			v[k] = vs
+		}
+	}
+	if value != "" {
+		v.Set(key, value)
+	}
+	if len(v) == 0 {
+		return "?"
+	}
+	return "?" + v.Encode()
+}
+
+// loadPublishedPage — страница опубликованных постов по курсору из запроса:
+// ?before= — более старые, ?after= — более новые. where — условие выборки
+// с плейсхолдерами $1..$len(args), ключ сортировки — (published_at, id).
+// Неразборчивый курсор открывает первую страницу.
+func loadPublishedPage(ctx context.Context, q url.Values, where string, args ...any) ([]models.Post, Pager, error) {
+	var pager Pager
+	n := len(args)
+	sql := "SELECT " + postColumns + " FROM post WHERE status='published' AND (" + where + ")"
+
+	before, hasBefore := parsePostCursor(q.Get("before"))
+	after, hasAfter := parsePostCursor(q.Get("after"))
+	switch {
+	case hasBefore:
+		sql += fmt.Sprintf(" AND (published_at, id) < ($%d, $%d) ORDER BY published_at DESC, id DESC", n+1, n+2)
+		args = append(args, before.At, before.ID)
+	case hasAfter:
+		// Идём назад: ближайшие более новые по возрастанию, потом разворачиваем
+		sql += fmt.Sprintf(" AND (published_at, id) > ($%d, $%d) ORDER BY published_at ASC, id ASC", n+1, n+2)
+		args = append(args, after.At, after.ID)
+	default:
+		sql += " ORDER BY published_at DESC, id DESC"
+	}
+	sql += fmt.Sprintf(" LIMIT %d", publicPageSize+1)
+
+	posts, err := queryPosts(ctx, sql, args...)
+	if err != nil {
+		return nil, pager, err
+	}
+	more := len(posts) > publicPageSize
+	if more {
+		posts = posts[:publicPageSize]
+	}
+	if hasAfter && !hasBefore {
+		for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+			posts[i], posts[j] = posts[j], posts[i]
+		}
+	}
+	if len(posts) == 0 {
+		// Устаревший курсор (посты удалили) — предложим вернуться в начало
+		if hasBefore || hasAfter {
+			pager.Prev = pageLink(q, "", "")
+		}
+		return posts, pager, nil
+	}
+
+	first, last := cursorOf(posts[0]), cursorOf(posts[len(posts)-1])
+	switch {
+	case hasBefore:
+		pager.Prev = pageLink(q, "after", first.String())
+		if more {
+			pager.Next = pageLink(q, "before", last.String())
+		}
+	case hasAfter:
+		// Раньше этой страницы точно что-то было — мы пришли оттуда
+		pager.Next = pageLink(q, "before", last.String())
+		if more {
+			pager.Prev = pageLink(q, "after", first.String())
+		}
+	default:
+		if more {
+			pager.Next = pageLink(q, "before", last.String())
+		}
+	}
+	return posts, pager, nil
+}
+
+// loadAdminPosts — страница списка постов пользователя в админке:
+// ?q= — полнотекстовый поиск (плюс подстрока в названии), результаты
+// по релевантности; ?page= — номер страницы с 1. Старые глобальные
+// посты (user_id IS NULL) тоже показываются.
+func loadAdminPosts(ctx context.Context, uid int, q url.Values) ([]models.Post, Pager, error) {
+	var pager Pager
+	search := strings.TrimSpace(q.Get("q"))
+	where := "(user_id=$1 OR user_id IS NULL) AND ($2 = '' OR search @@ " + tsQuery(2) + ` OR label ILIKE $3 ESCAPE '\')`
+	like := likeContains(search)
+	order := "id DESC"
+	if search != "" {
+		order = "ts_rank_cd(search, " + tsQuery(2) + ") DESC, id DESC"
+	}
+
+	var total int
+	if err := db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM post WHERE "+where, uid, search, like).Scan(&total); err != nil {
+		return nil, pager, err
+	}
+	pager.Pages = (total + adminPageSize - 1) / adminPageSize
+	if pager.Pages == 0 {
+		pager.Pages = 1
+	}
+	pager.Page, _ = strconv.Atoi(q.Get("page"))
+	if pager.Page < 1 {
+		pager.Page = 1
+	}
+	if pager.Page > pager.Pages {
+		pager.Page = pager.Pages
+	}
+
+	posts, err := queryPosts(ctx,
+		"SELECT "+postColumns+" FROM post WHERE "+where+" ORDER BY "+order+" LIMIT $4 OFFSET $5",
+		uid, search, like, adminPageSize, (pager.Page-1)*adminPageSize)
+	if err != nil {
+		return nil, pager, err
+	}
+	if pager.Page > 1 {
+		pager.Prev = pageLink(q, "page", strconv.Itoa(pager.Page-1))
+	}
+	if pager.Page < pager.Pages {
+		pager.Next = pageLink(q, "page", strconv.Itoa(pager.Page+1))
+	}
+	return posts, pager, nil
+}
+
+// likeEscaper экранирует спецсимволы LIKE (для ESCAPE '\')
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// likeContains — шаблон LIKE «содержит подстроку s» буквально: % и _ из
+// поисковой строки ищутся как символы, а не как подстановки
+func likeContains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
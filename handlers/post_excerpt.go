@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"html/template"
+	"strings"
+
+	"Site/markdown"
+	"Site/models"
+)
+
+// excerptRunes — длина превью из текста поста без разметки
+const excerptRunes = 200
+
+// PostExcerpt — превью поста для карточки
+type PostExcerpt struct {
+	HTML template.HTML
+	// More — в посте есть что-то сверх превью
+	More bool
+	// Minutes — примерное время чтения всего поста
+	Minutes int
+}
+
+// postExcerpt строит превью: краткое описание автора, иначе текст до
+// <!--more-->, иначе начало текста без разметки. Markdown не режется
+// посередине, поэтому в превью не бывает половины ссылки или блока кода.
+func postExcerpt(p models.Post) PostExcerpt {
+	e := PostExcerpt{Minutes: markdown.ReadingMinutes(p.Text)}
+	if s := strings.TrimSpace(p.Summary); s != "" {
+		e.HTML, e.More = renderMarkdown(s, nil), true
+		return e
+	}
+	if html, ok := markdown.BeforeMore(p.Text, mdPolicy); ok {
+		e.HTML, e.More = html, true
+		return e
+	}
+	text, cut := markdown.Excerpt(p.Text, excerptRunes)
+	e.HTML = template.HTML("<p>" + template.HTMLEscapeString(text) + "</p>")
+	e.More = cut
+	return e
+}
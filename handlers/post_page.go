@@ -0,0 +1,136 @@
+// handlers/post_page.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+
+	"Site/db"
+	"Site/logger"
+	"Site/markdown"
+	"Site/models"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostViewData — данные страницы поста
+type PostViewData struct {
+	Slug string
+	Post models.Post
+	// HTML — отрендеренный текст поста
+	HTML template.HTML
+	// TOC — оглавление длинного поста, пусто у коротких
+	TOC []TOCItem
+	// Preview — автор смотрит свой неопубликованный пост
+	Preview bool
+}
+
+// TOCItem — пункт оглавления; Depth — вложенность относительно
+// самого крупного заголовка в оглавлении
+type TOCItem struct {
+	markdown.Heading
+	Depth int
+}
+
+// Оглавление показывается, если в посте не меньше tocMinHeadings
+// заголовков уровня до tocMaxLevel
+const (
+	tocMinHeadings = 3
+	tocMaxLevel    = 3
+)
+
+// postTOC — оглавление из заголовков поста
+func postTOC(headings []markdown.Heading) []TOCItem {
+	var items []TOCItem
+	top := tocMaxLevel
+	for _, h := range headings {
+		if h.Level <= tocMaxLevel {
+			items = append(items, TOCItem{Heading: h})
+			top = min(top, h.Level)
+		}
+	}
+	if len(items) < tocMinHeadings {
+		return nil
+	}
+	for i := range items {
+		items[i].Depth = items[i].Level - top
+	}
+	return items
+}
+
+// loadUserPost — пост автора по слагу (в любом состоянии)
+func loadUserPost(ctx context.Context, uid int, slug string) (models.Post, error) {
+	return scanPost(db.Pool.QueryRow(ctx,
+		"SELECT "+postColumns+" FROM post WHERE user_id=$1 AND slug=$2", uid, slug))
+}
+
+// postSlugRedirect — текущий слаг поста, которому раньше принадлежал slug
+func postSlugRedirect(ctx context.Context, uid int, slug string) (string, error) {
+	var current string
+	err := db.Pool.QueryRow(ctx, `
+        SELECT p.slug FROM post_slug_redirects r JOIN post p ON p.id = r.post_id
+         WHERE r.user_id = $1 AND r.slug = $2`, uid, slug).Scan(&current)
+	return current, err
+}
+
+// PostPage — отдельная страница поста: "/{slug}/posts/{post}".
+// Старый слаг переименованного поста отвечает 301 на новый адрес.
+// Неопубликованный пост видит только автор.
+func PostPage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	s, err := settingsBySlug(r.Context(), vars["slug"])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	p, err := loadUserPost(r.Context(), s.UserID, vars["post"])
+	if errors.Is(err, pgx.ErrNoRows) {
+		current, rerr := postSlugRedirect(r.Context(), s.UserID, vars["post"])
+		if rerr != nil {
+			if !errors.Is(rerr, pgx.ErrNoRows) {
+				logger.Errorf("PostPage: select redirect %q error (uid=%d): %v", vars["post"], s.UserID, rerr)
+			}
+			http.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, models.Post{Slug: current}.Path(s.Slug), http.StatusMovedPermanently)
+		return
+	}
+	if err != nil {
+		logger.Errorf("PostPage: select post %q error (uid=%d): %v", vars["post"], s.UserID, err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+
+	withTags := []models.Post{p}
+	if err := attachPostTags(r.Context(), withTags); err != nil {
+		logger.Errorf("PostPage: select tags error (post=%d): %v", p.ID, err)
+	}
+	data := PostViewData{Slug: s.Slug, Post: withTags[0]}
+	if !p.IsPublished() {
+		if uid, ok := CurrentUserID(r); !ok || uid != s.UserID {
+			http.NotFound(w, r)
+			return
+		}
+		data.Preview = true
+	}
+	doc := markdown.RenderDocument(p.Text, mdPolicy)
+	data.HTML, data.TOC = doc.HTML, postTOC(doc.Headings)
+
+	tmpl := template.Must(template.ParseFiles(
+		"templates/header.html",
+		"templates/post.html",
+		"templates/footer.html",
+	))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if data.Preview {
+		w.Header().Set("X-Robots-Tag", "noindex")
+	}
+	if err := tmpl.ExecuteTemplate(w, "post", data); err != nil {
+		logger.Errorf("PostPage: template render error: %v", err)
+	}
+}
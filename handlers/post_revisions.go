@@ -0,0 +1,170 @@
+// handlers/post_revisions.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"Site/db"
+	"Site/logger"
+	"Site/models"
+	"Site/textdiff"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Ревизии постов: SavePost, UpdatePost и восстановление пишут в
+// post_revisions полную копию заголовка и текста, так что неудачная правка
+// ничего не теряет. Восстановление — не откат, а новая ревизия со старым
+// содержимым: история только растёт.
+
+// recordPostRevision сохраняет заголовок и текст поста как новую ревизию.
+// Сохранение без изменений (например, сменилось только состояние) ревизию
+// не плодит.
+func recordPostRevision(ctx context.Context, tx pgx.Tx, postID, authorID int, label, text string, restoredFrom *int) error {
+	_, err := tx.Exec(ctx, `
+        INSERT INTO post_revisions (post_id, author_id, label, text, restored_from)
+        SELECT $1, $2, $3, $4, $5
+         WHERE NOT EXISTS (
+               SELECT 1 FROM (SELECT label, text FROM post_revisions
+                               WHERE post_id = $1 ORDER BY id DESC LIMIT 1) last
+                WHERE last.label = $3 AND last.text = $4)
+            OR $5::int IS NOT NULL`,
+		postID, authorID, label, text, restoredFrom)
+	return err
+}
+
+const postRevisionColumns = `r.id, r.post_id, r.author_id,
+        COALESCE((SELECT COALESCE(NULLIF(u.email,''), u.username, '') FROM users u WHERE u.id = r.author_id), ''),
+        r.label, r.text, r.restored_from, r.created_at`
+
+func scanPostRevision(row pgx.Row) (models.PostRevision, error) {
+	var rev models.PostRevision
+	err := row.Scan(&rev.ID, &rev.PostID, &rev.AuthorID, &rev.Author, &rev.Label, &rev.Text, &rev.RestoredFrom, &rev.CreatedAt)
+	return rev, err
+}
+
+// loadPostRevisions — ревизии поста, новые сверху
+func loadPostRevisions(ctx context.Context, postID int) ([]models.PostRevision, error) {
+	rows, err := db.Pool.Query(ctx, `
+        SELECT `+postRevisionColumns+` FROM post_revisions r WHERE r.post_id = $1 ORDER BY r.id DESC`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.PostRevision
+	for rows.Next() {
+		rev, err := scanPostRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, rev)
+	}
+	return list, rows.Err()
+}
+
+// PostRevisionsViewData — контекст для post_revisions.html
+type PostRevisionsViewData struct {
+	Post      models.Post
+	Revisions []models.PostRevision
+	// From и To — сравниваемые ревизии (From старее); nil, если ревизия одна
+	From, To *models.PostRevision
+	Diff     []textdiff.Line
+	// LabelChanged — заголовки From и To различаются
+	LabelChanged bool
+}
+
+// PostRevisionsPage — история поста и построчное сравнение двух ревизий:
+// /admin/posts/revisions?id=POST[&from=REV&to=REV]. По умолчанию
+// сравниваются последняя ревизия и предыдущая.
+func PostRevisionsPage(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+	p, err := scanPost(db.Pool.QueryRow(r.Context(),
+		"SELECT "+postColumns+" FROM post WHERE id=$1 AND user_id=$2", id, uid))
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logger.Errorf("PostRevisionsPage: load post error (id=%d, uid=%d): %v", id, uid, err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	revs, err := loadPostRevisions(r.Context(), p.ID)
+	if err != nil {
+		logger.Errorf("PostRevisionsPage: load revisions error (post=%d): %v", p.ID, err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+
+	data := PostRevisionsViewData{Post: p, Revisions: revs}
+	pick := func(param string, def int) *models.PostRevision {
+		want, err := strconv.Atoi(r.URL.Query().Get(param))
+		for i := range revs {
+			if (err == nil && revs[i].ID == want) || (err != nil && i == def) {
+				return &revs[i]
+			}
+		}
+		return nil
+	}
+	data.To, data.From = pick("to", 0), pick("from", 1)
+	if data.From != nil && data.To != nil {
+		if data.From.ID > data.To.ID {
+			data.From, data.To = data.To, data.From
+		}
+		data.Diff = textdiff.Lines(data.From.Text, data.To.Text)
+		data.LabelChanged = data.From.Label != data.To.Label
+	}
+
+	tmpl := template.Must(template.ParseFiles("templates/admin/post_revisions.html"))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(w, "post_revisions", data); err != nil {
+		logger.Errorf("PostRevisionsPage: template render error: %v", err)
+	}
+}
+
+// RestorePostRevision возвращает посту заголовок и текст ревизии и
+// записывает это как новую ревизию
+func RestorePostRevision(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	revID, _ := strconv.Atoi(r.FormValue("revision_id"))
+	var postID int
+	err := pgx.BeginFunc(r.Context(), db.Pool, func(tx pgx.Tx) error {
+		rev, err := scanPostRevision(tx.QueryRow(r.Context(), `
+            SELECT `+postRevisionColumns+`
+              FROM post_revisions r JOIN post p ON p.id = r.post_id
+             WHERE r.id = $1 AND p.user_id = $2`, revID, uid))
+		if err != nil {
+			return err
+		}
+		postID = rev.PostID
+		if _, err := tx.Exec(r.Context(),
+			`UPDATE post SET label=$1, text=$2, updated_at=NOW() WHERE id=$3 AND user_id=$4`,
+			rev.Label, rev.Text, rev.PostID, uid); err != nil {
+			return err
+		}
+		return recordPostRevision(r.Context(), tx, rev.PostID, uid, rev.Label, rev.Text, &rev.ID)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logger.Errorf("RestorePostRevision: restore error (rev=%d, uid=%d): %v", revID, uid, err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/admin/posts/revisions?id="+strconv.Itoa(postID), 303)
+}
@@ -0,0 +1,53 @@
+// handlers/post_scheduler.go
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"Site/db"
+	"Site/logger"
+)
+
+// PostScheduler публикует отложенные посты, когда подходит их published_at
+type PostScheduler struct {
+	Tick time.Duration
+}
+
+// NewPostScheduler создаёт планировщик с проверкой раз в минуту
+// (точность отложенной публикации — до минуты, как и у поля в редакторе)
+func NewPostScheduler() *PostScheduler {
+	return &PostScheduler{Tick: time.Minute}
+}
+
+// Run публикует созревшие посты на каждом тике до отмены ctx
+func (s *PostScheduler) Run(ctx context.Context) {
+	logger.Infof("PostScheduler: started (tick %s)", s.Tick)
+	t := time.NewTicker(s.Tick)
+	defer t.Stop()
+	for {
+		if n, err := s.RunOnce(ctx); err != nil {
+			logger.Errorf("PostScheduler: %v", err)
+		} else if n > 0 {
+			logger.Infof("PostScheduler: published %d scheduled posts", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunOnce переводит в published все отложенные посты с наступившим
+// временем и возвращает их число. Один UPDATE, так что два инстанса не
+// опубликуют пост дважды.
+func (s *PostScheduler) RunOnce(ctx context.Context) (int64, error) {
+	tag, err := db.Pool.Exec(ctx, `
+        UPDATE post SET status = 'published', updated_at = NOW()
+         WHERE status = 'scheduled' AND published_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,140 @@
+// handlers/post_slug.go
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"Site/db"
+	"Site/logger"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Слаг поста — часть адреса /{slug}/posts/{post-slug}, уникальная среди
+// постов автора. Делается из заголовка: кириллица транслитерируется,
+// диакритика снимается (é → e), остальное становится дефисами. Старые
+// слаги после переименования остаются в post_slug_redirects и отвечают 301.
+
+// postSlugMaxLen — предел длины слага, длинные заголовки обрезаются по слову
+const postSlugMaxLen = 80
+
+// cyrillicTranslit — русская и украинская кириллица латиницей (как в
+// адресах Яндекса и Википедии: щ → shch, х → kh, ъ/ь пропадают)
+var cyrillicTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "",
+	'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
+
+// postSlug делает слаг из заголовка; пустой результат — "post"
+func postSlug(label string) string {
+	slug, _ := translitSlug(label)
+	if slug == "" {
+		slug = "post"
+	}
+	return slug
+}
+
+// translitSlug — слаг из текста без запасного значения (может быть пустым);
+// lossy — в тексте были буквы или цифры, которые не удалось передать
+// латиницей (иероглифы, арабское письмо), и слаг не различает такие тексты
+func translitSlug(label string) (slug string, lossy bool) {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(label) {
+		part, known := cyrillicTranslit[r]
+		if !known {
+			// NFD раскладывает «é» на «e» и комбинируемый знак, который отбрасывается
+			for _, d := range norm.NFD.String(string(r)) {
+				if d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)) {
+					part += string(d)
+				}
+			}
+			if part == "" && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				lossy = true
+			}
+		}
+		if part != "" {
+			b.WriteString(part)
+			dash = false
+		} else if !known && !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug = strings.TrimSuffix(b.String(), "-")
+	if len(slug) > postSlugMaxLen {
+		slug = slug[:postSlugMaxLen]
+		if i := strings.LastIndexByte(slug, '-'); i > postSlugMaxLen/2 {
+			slug = slug[:i]
+		}
+		slug = strings.TrimSuffix(slug, "-")
+	}
+	return slug, lossy
+}
+
+// uniquePostSlug добавляет к слагу -2, -3…, пока он занят другим постом автора
+func uniquePostSlug(ctx context.Context, q pgxQuerier, uid, postID int, base string) (string, error) {
+	slug := base
+	for n := 2; ; n++ {
+		var exists bool
+		if err := q.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM post WHERE user_id=$1 AND slug=$2 AND id<>$3)`,
+			uid, slug, postID).Scan(&exists); err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// pgxQuerier — пул или транзакция
+type pgxQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// BackfillPostSlugs выдаёт слаги постам, созданным до их появления
+func BackfillPostSlugs(ctx context.Context) {
+	rows, err := db.Pool.Query(ctx, `SELECT id, COALESCE(user_id, 0), label FROM post WHERE slug = '' ORDER BY id`)
+	if err != nil {
+		logger.Errorf("BackfillPostSlugs: select error: %v", err)
+		return
+	}
+	type pending struct {
+		id, uid int
+		label   string
+	}
+	var list []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.uid, &p.label); err != nil {
+			rows.Close()
+			logger.Errorf("BackfillPostSlugs: scan error: %v", err)
+			return
+		}
+		list = append(list, p)
+	}
+	rows.Close()
+
+	for _, p := range list {
+		slug, err := uniquePostSlug(ctx, db.Pool, p.uid, p.id, postSlug(p.label))
+		if err == nil {
+			_, err = db.Pool.Exec(ctx, `UPDATE post SET slug=$1 WHERE id=$2`, slug, p.id)
+		}
+		if err != nil {
+			logger.Errorf("BackfillPostSlugs: post %d error: %v", p.id, err)
+		}
+	}
+	if len(list) > 0 {
+		logger.Infof("BackfillPostSlugs: assigned slugs to %d posts", len(list))
+	}
+}
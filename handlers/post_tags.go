@@ -0,0 +1,233 @@
+// handlers/post_tags.go
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"Site/db"
+	"Site/feed"
+	"Site/logger"
+	"Site/models"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// Теги постов. У каждого автора свой набор; тег создаётся, когда его
+// впервые пишут в форме поста, и удаляется вместе с последним постом.
+
+// tagSymbols — символы, которые в именах тегов значимы (C++ и C# — не «c»)
+var tagSymbols = strings.NewReplacer("+", " plus ", "#", " sharp ")
+
+// tagSlug — слаг тега для адресов, та же транслитерация, что у постов.
+// Если латиницей имя передаётся не целиком («日本», «😀», «!!!»), к слагу
+// добавляется хэш имени: иначе разные теги получили бы один слаг и
+// ON CONFLICT в setPostTags молча слил бы их в один.
+func tagSlug(name string) string {
+	slug, lossy := translitSlug(tagSymbols.Replace(name))
+	if slug != "" && !lossy {
+		return slug
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(name))))
+	suffix := hex.EncodeToString(sum[:5])
+	if slug == "" {
+		return "tag-" + suffix
+	}
+	return slug + "-" + suffix
+}
+
+// setPostTags заменяет теги поста на names (имена как в форме, без повторов)
+func setPostTags(ctx context.Context, tx pgx.Tx, uid, postID int, names []string) error {
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		var id int
+		// Имя с тем же слагом («Go» и «go») — тот же тег, имя остаётся первым
+		if err := tx.QueryRow(ctx, `
+            INSERT INTO tags (user_id, name, slug) VALUES ($1, $2, $3)
+            ON CONFLICT (user_id, slug) DO UPDATE SET name = tags.name
+            RETURNING id`, uid, name, tagSlug(name)).Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM post_tags WHERE post_id = $1 AND NOT (tag_id = ANY($2))`, postID, ids); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+        INSERT INTO post_tags (post_id, tag_id) SELECT $1, unnest($2::int[])
+        ON CONFLICT DO NOTHING`, postID, ids); err != nil {
+		return err
+	}
+	return pruneTags(ctx, tx, uid)
+}
+
+// pruneTags удаляет теги автора, у которых не осталось постов
+func pruneTags(ctx context.Context, tx pgx.Tx, uid int) error {
+	_, err := tx.Exec(ctx, `
+        DELETE FROM tags t
+         WHERE t.user_id = $1 AND NOT EXISTS (SELECT 1 FROM post_tags pt WHERE pt.tag_id = t.id)`, uid)
+	return err
+}
+
+// attachPostTags заполняет Tags у постов одним запросом
+func attachPostTags(ctx context.Context, posts []models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	ids := make([]int, len(posts))
+	byID := make(map[int]*models.Post, len(posts))
+	for i := range posts {
+		ids[i] = posts[i].ID
+		byID[posts[i].ID] = &posts[i]
+	}
+	rows, err := db.Pool.Query(ctx, `
+        SELECT pt.post_id, t.id, t.user_id, t.name, t.slug
+          FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+         WHERE pt.post_id = ANY($1)
+         ORDER BY lower(t.name)`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var postID int
+		var t models.Tag
+		if err := rows.Scan(&postID, &t.ID, &t.UserID, &t.Name, &t.Slug); err != nil {
+			return err
+		}
+		if p := byID[postID]; p != nil {
+			p.Tags = append(p.Tags, t)
+		}
+	}
+	return rows.Err()
+}
+
+// loadPublishedTags — теги автора, у которых есть опубликованные посты,
+// с их числом; для облака тегов на публичной странице
+func loadPublishedTags(ctx context.Context, uid int) ([]models.Tag, error) {
+	rows, err := db.Pool.Query(ctx, `
+        SELECT t.id, t.user_id, t.name, t.slug, COUNT(*)
+          FROM tags t
+          JOIN post_tags pt ON pt.tag_id = t.id
+          JOIN post p ON p.id = pt.post_id AND p.status = 'published'
+         WHERE t.user_id = $1
+         GROUP BY t.id
+         ORDER BY lower(t.name)`, uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tags []models.Tag
+	for rows.Next() {
+		var t models.Tag
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Slug, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// tagBySlug — тег автора по слагу
+func tagBySlug(ctx context.Context, uid int, slug string) (models.Tag, error) {
+	var t models.Tag
+	err := db.Pool.QueryRow(ctx,
+		`SELECT id, user_id, name, slug FROM tags WHERE user_id = $1 AND slug = $2`, uid, slug).
+		Scan(&t.ID, &t.UserID, &t.Name, &t.Slug)
+	return t, err
+}
+
+// loadTaggedPosts — опубликованные посты автора с тегом, новые сверху
+func loadTaggedPosts(ctx context.Context, uid, tagID int) ([]models.Post, error) {
+	return queryPosts(ctx, `
+        SELECT `+postColumns+` FROM post
+         WHERE user_id = $1 AND status = 'published'
+           AND EXISTS (SELECT 1 FROM post_tags pt WHERE pt.post_id = post.id AND pt.tag_id = $2)
+         ORDER BY published_at DESC, id DESC`, uid, tagID)
+}
+
+// TagViewData — данные страницы тега
+type TagViewData struct {
+	Slug  string
+	Tag   models.Tag
+	Posts []models.Post
+	// FeedPath — лента тега
+	FeedPath string
+}
+
+// loadTagArchive — автор, тег и его опубликованные посты по адресу
+// /{slug}/tags/{tag}; pgx.ErrNoRows, если автора или тега нет
+func loadTagArchive(r *http.Request) (*models.Settings, models.Tag, []models.Post, error) {
+	vars := mux.Vars(r)
+	s, err := settingsBySlug(r.Context(), vars["slug"])
+	if err != nil {
+		return nil, models.Tag{}, nil, pgx.ErrNoRows
+	}
+	t, err := tagBySlug(r.Context(), s.UserID, vars["tag"])
+	if err != nil {
+		return nil, models.Tag{}, nil, err
+	}
+	posts, err := loadTaggedPosts(r.Context(), s.UserID, t.ID)
+	if err == nil {
+		err = attachPostTags(r.Context(), posts)
+	}
+	return s, t, posts, err
+}
+
+// TagPage — архив постов с тегом: "/{slug}/tags/{tag}"
+func TagPage(w http.ResponseWriter, r *http.Request) {
+	s, t, posts, err := loadTagArchive(r)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logger.Errorf("TagPage: load tag %q error: %v", mux.Vars(r)["tag"], err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	data := TagViewData{Slug: s.Slug, Tag: t, Posts: posts, FeedPath: t.Path(s.Slug) + "/feed.xml"}
+	tmpl := template.Must(template.ParseFiles(
+		"templates/header.html",
+		"templates/tag.html",
+		"templates/footer.html",
+	))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(w, "tag", data); err != nil {
+		logger.Errorf("TagPage: template render error: %v", err)
+	}
+}
+
+// TagFeed — RSS постов с тегом: "/{slug}/tags/{tag}/feed.xml"
+func TagFeed(w http.ResponseWriter, r *http.Request) {
+	s, t, posts, err := loadTagArchive(r)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logger.Errorf("TagFeed: load tag %q error: %v", mux.Vars(r)["tag"], err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	if len(posts) > feedLimit {
+		posts = posts[:feedLimit]
+	}
+	base := siteURL(r)
+	items := postFeedItems(base, s.Slug, posts)
+	writeFeed(w, r, feed.Feed{
+		Title:       s.Slug + " — " + t.Name,
+		Description: "Посты с тегом «" + t.Name + "»",
+		Link:        base + t.Path(s.Slug),
+		Self:        base + t.Path(s.Slug) + "/feed.xml",
+		Updated:     feedUpdated(items),
+		Items:       items,
+	}, feedRSS)
+}
@@ -5,39 +5,98 @@ import (
 	"Site/logger"
 	"Site/models"
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
-	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
-func AdminPosts(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Pool.Query(context.Background(),
-		"SELECT id,label,text FROM post ORDER BY id DESC")
+// postColumns — столбцы post в порядке scanPost
+const postColumns = `id, label, text, summary, status, slug, created_at, updated_at, published_at`
+
+func scanPost(row pgx.Row) (models.Post, error) {
+	var p models.Post
+	err := row.Scan(&p.ID, &p.Label, &p.Text, &p.Summary, &p.Status, &p.Slug, &p.CreatedAt, &p.UpdatedAt, &p.PublishedAt)
+	return p, err
+}
+
+// queryPosts выполняет запрос, выбирающий postColumns
+func queryPosts(ctx context.Context, sql string, args ...any) ([]models.Post, error) {
+	rows, err := db.Pool.Query(ctx, sql, args...)
 	if err != nil {
-		logger.Errorf("AdminPosts: load posts error: %v", err)
-		http.Error(w, "DB error", 500)
-		return
+		return nil, err
 	}
 	defer rows.Close()
-
 	var posts []models.Post
 	for rows.Next() {
-		var p models.Post
-		if err := rows.Scan(&p.ID, &p.Label, &p.Text); err != nil {
-			log.Println(err)
-			continue
+		p, err := scanPost(rows)
+		if err != nil {
+			return nil, err
 		}
 		posts = append(posts, p)
 	}
+	return posts, rows.Err()
+}
+
+// parsePostState читает из формы состояние поста и время публикации.
+// publish_at приходит из <input type="datetime-local"> в часовом поясе
+// браузера, его смещение — в tz_offset (минуты, как getTimezoneOffset).
+// Время в будущем у опубликованного поста делает его отложенным, а
+// отложенный с прошедшим временем публикуется сразу.
+func parsePostState(r *http.Request, now time.Time) (string, *time.Time, error) {
+	status := r.FormValue("status")
+	switch status {
+	case "":
+		status = models.PostPublished
+	case models.PostDraft, models.PostPublished, models.PostScheduled, models.PostArchived:
+	default:
+		return "", nil, fmt.Errorf("неизвестное состояние %q", status)
+	}
+
+	var at *time.Time
+	if v := strings.TrimSpace(r.FormValue("publish_at")); v != "" {
+		loc := time.Local
+		if off, err := strconv.Atoi(r.FormValue("tz_offset")); err == nil {
+			loc = time.FixedZone("", -off*60)
+		}
+		t, err := time.ParseInLocation("2006-01-02T15:04", v, loc)
+		if err != nil {
+			return "", nil, errors.New("неверная дата публикации")
+		}
+		at = &t
+	}
+
+	switch {
+	case status == models.PostScheduled && at == nil:
+		return "", nil, errors.New("для отложенной публикации укажите дату")
+	case status == models.PostScheduled && !at.After(now):
+		status = models.PostPublished
+	case status == models.PostPublished && at != nil && at.After(now):
+		status = models.PostScheduled
+	}
+	return status, at, nil
+}
+
+func AdminPosts(w http.ResponseWriter, r *http.Request) {
+	posts, err := queryPosts(context.Background(),
+		"SELECT "+postColumns+" FROM post ORDER BY id DESC")
+	if err != nil {
+		logger.Errorf("AdminPosts: load posts error: %v", err)
+		http.Error(w, "DB error", 500)
+		return
+	}
 
 	data := AdminViewData{
 		ActiveTab: "posts",
 		Posts:     posts,
 		Edit:      nil,
 	}
-	tmpl := template.Must(template.ParseFiles("templates/admin/admin.html"))
+	tmpl := template.Must(template.ParseFiles("templates/admin/admin.html", "templates/admin/projects_table.html"))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	tmpl.ExecuteTemplate(w, "admin", data)
 }
@@ -48,6 +107,7 @@ func SavePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	label, text := r.FormValue("label_post"), r.FormValue("text_post")
+	summary := strings.TrimSpace(r.FormValue("summary_post"))
 	if label == "" || text == "" {
 		http.Error(w, "All fields required", 400)
 		return
@@ -57,10 +117,40 @@ func SavePost(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	_, err := db.Pool.Exec(context.Background(),
-		"INSERT INTO post(label,text,user_id) VALUES($1,$2,$3)", label, text, uid)
+	status, publishAt, err := parsePostState(r, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	base := postSlug(label)
+	if v := strings.TrimSpace(r.FormValue("slug_post")); v != "" {
+		base = postSlug(v)
+	}
+	err = pgx.BeginFunc(r.Context(), db.Pool, func(tx pgx.Tx) error {
+		slug, err := uniquePostSlug(r.Context(), tx, uid, 0, base)
+		if err != nil {
+			return err
+		}
+		// У опубликованного без даты published_at — момент сохранения
+		var id int
+		if err := tx.QueryRow(r.Context(), `
+            INSERT INTO post(label, text, user_id, status, slug, published_at, summary)
+            VALUES($1, $2, $3, $4, $5, CASE WHEN $4 = 'published' THEN COALESCE($6, NOW()) ELSE $6 END, $7)
+            RETURNING id`,
+			label, text, uid, status, slug, publishAt, summary).Scan(&id); err != nil {
+			return err
+		}
+		if err := recordPostRevision(r.Context(), tx, id, uid, label, text, nil); err != nil {
+			return err
+		}
+		if err := setPostTags(r.Context(), tx, uid, id, parseTags(r.FormValue("tags_post"))); err != nil {
+			return err
+		}
+		// Слаг мог раньше принадлежать переименованному посту — теперь он занят
+		_, err = tx.Exec(r.Context(), `DELETE FROM post_slug_redirects WHERE user_id=$1 AND slug=$2`, uid, slug)
+		return err
+	})
 	if err != nil {
-		fmt.Println("insert error:", err)
 		logger.Errorf("SavePost: insert error (uid=%d): %v", uid, err)
 		http.Error(w, "DB error", 500)
 		return
@@ -75,7 +165,13 @@ func DeletePost(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	if _, err := db.Pool.Exec(context.Background(), "DELETE FROM post WHERE id=$1 AND user_id=$2", id, uid); err != nil {
+	err := pgx.BeginFunc(r.Context(), db.Pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(r.Context(), "DELETE FROM post WHERE id=$1 AND user_id=$2", id, uid); err != nil {
+			return err
+		}
+		return pruneTags(r.Context(), tx, uid)
+	})
+	if err != nil {
 		logger.Errorf("DeletePost: delete error (id=%d, uid=%d): %v", id, uid, err)
 	}
 	http.Redirect(w, r, "/admin", 303)
@@ -83,31 +179,42 @@ func DeletePost(w http.ResponseWriter, r *http.Request) {
 
 func EditPost(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(r.URL.Query().Get("id"))
-	var p models.Post
 	uid, _ := CurrentUserID(r)
-	if err := db.Pool.QueryRow(context.Background(),
-		"SELECT id,label,text FROM post WHERE id=$1 AND user_id=$2", id, uid).
-		Scan(&p.ID, &p.Label, &p.Text); err != nil {
+	p, err := scanPost(db.Pool.QueryRow(context.Background(),
+		"SELECT "+postColumns+" FROM post WHERE id=$1 AND user_id=$2", id, uid))
+	if err != nil {
 		logger.Errorf("EditPost: load error (id=%d, uid=%d): %v", id, uid, err)
 	}
+	edit := []models.Post{p}
+	if err := attachPostTags(r.Context(), edit); err != nil {
+		logger.Errorf("EditPost: load tags error (id=%d): %v", id, err)
+	}
+	p = edit[0]
 
-	// reload all posts
-	rows, _ := db.Pool.Query(context.Background(),
-		"SELECT id,label,text FROM post WHERE user_id=$1 ORDER BY id DESC", uid)
-	defer rows.Close()
-	var posts []models.Post
-	for rows.Next() {
-		var q models.Post
-		rows.Scan(&q.ID, &q.Label, &q.Text)
-		posts = append(posts, q)
+	// страница списка постов (?q=, ?page= сохраняются в ссылках)
+	posts, pager, err := loadAdminPosts(r.Context(), uid, r.URL.Query())
+	if err == nil {
+		err = attachPostTags(r.Context(), posts)
+	}
+	if err != nil {
+		logger.Errorf("EditPost: load posts error (uid=%d): %v", uid, err)
+	}
+
+	media, err := loadUserMedia(r.Context(), uid)
+	if err != nil {
+		logger.Errorf("EditPost: media query error (uid=%d): %v", uid, err)
 	}
 
 	data := AdminViewData{
-		ActiveTab: "posts",
-		Posts:     posts,
-		Edit:      &p,
+		ActiveTab:  "posts",
+		Posts:      posts,
+		PostSearch: strings.TrimSpace(r.URL.Query().Get("q")),
+		PostPager:  pager,
+		Edit:       &p,
+		Media:      media,
+		Settings:   userSettings(r.Context(), uid),
 	}
-	tmpl := template.Must(template.ParseFiles("templates/admin/admin.html"))
+	tmpl := template.Must(template.ParseFiles("templates/admin/admin.html", "templates/admin/projects_table.html"))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	tmpl.ExecuteTemplate(w, "admin", data)
 }
@@ -120,8 +227,81 @@ func UpdatePost(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	if _, err := db.Pool.Exec(context.Background(),
-		"UPDATE post SET label=$1,text=$2 WHERE id=$3 AND user_id=$4", label, text, id, uid); err != nil {
+	status, publishAt, err := parsePostState(r, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = pgx.BeginFunc(r.Context(), db.Pool, func(tx pgx.Tx) error {
+		var old string
+		if err := tx.QueryRow(r.Context(),
+			`SELECT slug FROM post WHERE id=$1 AND user_id=$2 FOR UPDATE`, id, uid).Scan(&old); err != nil {
+			return err
+		}
+		// Без поля slug_post (старая форма) слаг не меняется, пустое поле —
+		// слаг заново из заголовка
+		slug := old
+		if v, sent := r.Form["slug_post"]; sent {
+			base := postSlug(label)
+			if v := strings.TrimSpace(v[0]); v != "" {
+				base = postSlug(v)
+			}
+			if base != old {
+				var err error
+				if slug, err = uniquePostSlug(r.Context(), tx, uid, id, base); err != nil {
+					return err
+				}
+			}
+		}
+		if slug != old {
+			// Старый адрес продолжает работать через 301
+			if old != "" {
+				if _, err := tx.Exec(r.Context(), `
+                    INSERT INTO post_slug_redirects(user_id, slug, post_id) VALUES ($1, $2, $3)
+                    ON CONFLICT (user_id, slug) DO UPDATE SET post_id = EXCLUDED.post_id, created_at = NOW()`,
+					uid, old, id); err != nil {
+					return err
+				}
+			}
+			if _, err := tx.Exec(r.Context(),
+				`DELETE FROM post_slug_redirects WHERE user_id=$1 AND slug=$2`, uid, slug); err != nil {
+				return err
+			}
+		}
+		// Повторная публикация без даты сохраняет первую дату публикации;
+		// черновик и архив дату не трогают
+		if _, err := tx.Exec(r.Context(), `
+            UPDATE post
+               SET label=$1, text=$2, status=$5, slug=$7, updated_at=NOW(),
+                   published_at = CASE $5
+                       WHEN 'scheduled' THEN $6
+                       WHEN 'published' THEN COALESCE($6, published_at, NOW())
+                       ELSE published_at END
+             WHERE id=$3 AND user_id=$4`,
+			label, text, id, uid, status, publishAt, slug); err != nil {
+			return err
+		}
+		if err := recordPostRevision(r.Context(), tx, id, uid, label, text, nil); err != nil {
+			return err
+		}
+		// Без поля summary_post (старая форма) описание не меняется
+		if v, sent := r.Form["summary_post"]; sent {
+			if _, err := tx.Exec(r.Context(), `UPDATE post SET summary=$1 WHERE id=$2 AND user_id=$3`,
+				strings.TrimSpace(v[0]), id, uid); err != nil {
+				return err
+			}
+		}
+		// Без поля tags_post (старая форма) теги не меняются
+		if v, sent := r.Form["tags_post"]; sent {
+			return setPostTags(r.Context(), tx, uid, id, parseTags(v[0]))
+		}
+		return nil
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
 		logger.Errorf("UpdatePost: update error (id=%d, uid=%d): %v", id, uid, err)
 	}
 	http.Redirect(w, r, "/admin", 303)
@@ -3,204 +3,128 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
-	"io"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
-	"time"
 
 	"Site/db"
 	"Site/logger"
 	"Site/models"
+	"Site/providers"
+	"Site/uploads"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
 )
 
-// ghRepo описывает JSON-ответ GitHub API
-type ghRepo struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	HTMLURL     string `json:"html_url"`
-	Owner       struct {
-		AvatarURL string `json:"avatar_url"`
-	} `json:"owner"`
+// projectColumns — колонки projects в порядке scanProject
+const projectColumns = `
+        id, user_id, provider, repo_name, COALESCE(title,''), COALESCE(description,''), COALESCE(image_url,''),
+        COALESCE(github_url,''), COALESCE(custom_url,''), enabled, updated_at,
+        stars, forks, language, languages, topics, homepage, license, pushed_at, archived, source, links,
+        position, pinned, group_name`
+
+func scanProject(row pgx.Row) (models.Project, error) {
+	var p models.Project
+	err := row.Scan(&p.ID, &p.UserID, &p.Provider, &p.RepoName, &p.Title, &p.Description,
+		&p.ImageURL, &p.GitHubURL, &p.CustomURL, &p.Enabled, &p.UpdatedAt,
+		&p.Stars, &p.Forks, &p.Language, &p.Languages, &p.Topics, &p.Homepage, &p.License, &p.PushedAt, &p.Archived,
+		&p.Source, &p.Links, &p.Position, &p.Pinned, &p.Group)
+	return p, err
 }
 
-// getNextGitHubToken returns next enabled token by least recently used strategy.
-func getNextGitHubToken(ctx context.Context) (string, error) {
-	var token string
-	err := db.Pool.QueryRow(ctx, `
-        SELECT token
-          FROM github_tokens
-         WHERE enabled = true
-         ORDER BY COALESCE(last_used_at, to_timestamp(0)) ASC, id ASC
-         LIMIT 1`).Scan(&token)
+// loadUserProjects возвращает проекты владельца в ручном порядке
+// (закреплённые сверху); onlyEnabled — только включённые
+func loadUserProjects(ctx context.Context, uid int, onlyEnabled bool) ([]models.Project, error) {
+	rows, err := db.Pool.Query(ctx, `
+        SELECT `+projectColumns+`
+          FROM projects
+         WHERE user_id = $1 AND (enabled OR NOT $2)
+         ORDER BY pinned DESC, position, id`, uid, onlyEnabled)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	// mark as used now
-	_, _ = db.Pool.Exec(ctx, `UPDATE github_tokens SET last_used_at = NOW() WHERE token = $1`, token)
-	return token, nil
-}
+	defer rows.Close()
 
-func disableGitHubToken(ctx context.Context, token string) {
-	_, _ = db.Pool.Exec(ctx, `UPDATE github_tokens SET enabled=false, fail_count = fail_count + 1 WHERE token=$1`, token)
+	var prjs []models.Project
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		prjs = append(prjs, p)
+	}
+	return prjs, rows.Err()
 }
 
 func RefreshProjects(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-	raw := strings.TrimSpace(r.FormValue("github_user"))
-	if raw != "" {
-		// Если это URL, извлекаем путь после github.com/
-		if u, err := url.Parse(raw); err == nil && strings.Contains(u.Host, "github.com") {
-			raw = strings.Trim(u.Path, "/")
-		}
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
-		parts := strings.Split(raw, "/")
-		var apiURL string
-		switch len(parts) {
-		case 1:
-			apiURL = fmt.Sprintf("https://api.github.com/users/%s/repos", parts[0])
-		case 2:
-			apiURL = fmt.Sprintf("https://api.github.com/repos/%s/%s", parts[0], parts[1])
-		default:
-			logger.Errorf("RefreshProjects: bad github path: %s", raw)
-			http.Error(w, "Неверный формат GitHub-пути", http.StatusBadRequest)
+	raw := strings.TrimSpace(r.FormValue("source"))
+	if raw != "" {
+		p, path, err := gitProviders.Resolve(raw)
+		if err != nil {
+			logger.Errorf("RefreshProjects: bad source %q: %v", raw, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		// Настройка HTTP-клиента и, при необходимости, прокси из окружения (GITHUB_PROXY)
-		var client *http.Client
-		if proxy := os.Getenv("GITHUB_PROXY"); strings.TrimSpace(proxy) != "" {
-			if proxyURL, errp := url.Parse(proxy); errp == nil {
-				transport := &http.Transport{Proxy: http.ProxyURL(proxyURL)}
-				client = &http.Client{Transport: transport, Timeout: 30 * time.Second}
-			}
-		}
-		if client == nil {
-			client = &http.Client{Timeout: 30 * time.Second}
+		// Запоминаем источник, чтобы фоновая синхронизация обновляла его дальше
+		opts := providers.ListOptions{
+			Forks:    r.FormValue("include_forks") == "on",
+			Archived: r.FormValue("include_archived") == "on",
+			Private:  r.FormValue("include_private") == "on",
 		}
-
-		// Try with token pool; if none available or all fail, fall back to unauthenticated
-		var resp *http.Response
-		var err error
-		tried := 0
-		maxTries := 10
-		for tried = 0; tried < maxTries; tried++ {
-			req, _ := http.NewRequest("GET", apiURL, nil)
-			req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-			token, terr := getNextGitHubToken(r.Context())
-			if terr == nil && token != "" {
-				req.Header.Set("Authorization", "token "+token)
-				resp, err = client.Do(req)
-				if err != nil {
-					// network error, try next token
-					continue
-				}
-				// Check rate limit headers and statuses
-				if resp.StatusCode == http.StatusUnauthorized { // 401
-					disableGitHubToken(r.Context(), token)
-					resp.Body.Close()
-					continue
-				}
-				if resp.StatusCode == http.StatusForbidden {
-					// If rate limit exceeded
-					if strings.Contains(strings.ToLower(resp.Status), "forbidden") {
-						// try next token; do not disable
-						resp.Body.Close()
-						continue
-					}
-				}
-				// otherwise accept this response
-				break
-			} else {
-				// No tokens available
-				break
-			}
-		}
-		if resp == nil && err == nil {
-			// fallback without token
-			req, _ := http.NewRequest("GET", apiURL, nil)
-			req.Header.Set("Accept", "application/vnd.github.v3+json")
-			resp, err = client.Do(req)
-		}
-		if err != nil {
-			logger.Errorf("RefreshProjects: request error: %v", err)
-			http.Error(w, "Ошибка запроса к GitHub: "+err.Error(), http.StatusBadGateway)
+		// Приватные репозитории видны токенам общего пула — импорт только для admin
+		if opts.Private && CurrentUserRole(r) != "admin" {
+			http.Error(w, "Импорт приватных репозиториев доступен только администратору", http.StatusForbidden)
 			return
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			logger.Errorf("RefreshProjects: GitHub API %d: %s", resp.StatusCode, string(body))
-			http.Error(w, fmt.Sprintf("GitHub API %d: %s", resp.StatusCode, body), http.StatusBadGateway)
+		src, err := saveProjectSource(r.Context(), uid, p.ID(), path, opts)
+		if err != nil {
+			logger.Errorf("RefreshProjects: save source %s/%s error: %v", p.ID(), path, err)
+			http.Error(w, "DB error", http.StatusInternalServerError)
 			return
 		}
 
-		// Парсим JSON
-		var repos []ghRepo
-		if len(parts) == 2 {
-			var single ghRepo
-			if err := json.NewDecoder(resp.Body).Decode(&single); err != nil {
-				logger.Errorf("RefreshProjects: JSON decode (single) error: %v", err)
-				http.Error(w, "Ошибка разбора JSON: "+err.Error(), http.StatusBadGateway)
-				return
-			}
-			repos = []ghRepo{single}
-		} else {
-			if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
-				logger.Errorf("RefreshProjects: JSON decode (list) error: %v", err)
-				http.Error(w, "Ошибка разбора JSON: "+err.Error(), http.StatusBadGateway)
-				return
-			}
-		}
-
-		// Upsert
-		for _, repo := range repos {
-			_, err := db.Pool.Exec(context.Background(), `
-                INSERT INTO projects(repo_name,title,description,image_url,github_url,enabled)
-                VALUES($1,$2,$3,$4,$5,false)
-                ON CONFLICT(repo_name) DO UPDATE SET
-                  title       = EXCLUDED.title,
-                  description = EXCLUDED.description,
-                  image_url   = EXCLUDED.image_url,
-                  github_url  = EXCLUDED.github_url,
-                  updated_at  = NOW()
-            `, repo.Name, repo.Name, repo.Description, repo.Owner.AvatarURL, repo.HTMLURL)
-			if err != nil {
-				logger.Errorf("RefreshProjects: upsert project %s error: %v", repo.Name, err)
-				http.Error(w, "DB upsert error for "+repo.Name+": "+err.Error(), http.StatusInternalServerError)
-				return
+		ctx, cancel := context.WithTimeout(r.Context(), manualSyncTimeout)
+		defer cancel()
+		if _, err := syncProjectSource(ctx, gitProviders, src, "manual"); err != nil {
+			var apiErr *providers.APIError
+			switch {
+			case errors.Is(err, errSyncBusy):
+				http.Error(w, "Синхронизация этого источника уже выполняется", http.StatusConflict)
+			case errors.Is(err, context.DeadlineExceeded):
+				http.Error(w, "Хостинг отвечает слишком долго — источник сохранён, попробуйте синхронизировать его позже", http.StatusGatewayTimeout)
+			case errors.Is(err, providers.ErrBadSource):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.As(err, &apiErr):
+				logger.Errorf("RefreshProjects: %v", err)
+				http.Error(w, err.Error(), http.StatusBadGateway)
+			default:
+				logger.Errorf("RefreshProjects: sync %s/%s error: %v", p.ID(), path, err)
+				http.Error(w, "Ошибка синхронизации: "+err.Error(), http.StatusBadGateway)
 			}
+			return
 		}
 	}
 
 	// Возвращаем актуальную таблицу
-	rows, err := db.Pool.Query(context.Background(), `
-        SELECT id, repo_name, title, description, image_url,
-               github_url, custom_url, enabled
-          FROM projects
-         ORDER BY updated_at DESC`)
+	prjs, err := loadUserProjects(r.Context(), uid, false)
 	if err != nil {
 		logger.Errorf("RefreshProjects: select projects error: %v", err)
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	var prjs []models.Project
-	for rows.Next() {
-		var p models.Project
-		rows.Scan(&p.ID, &p.RepoName, &p.Title, &p.Description,
-			&p.ImageURL, &p.GitHubURL, &p.CustomURL, &p.Enabled)
-		prjs = append(prjs, p)
-	}
 
 	tmpl := template.Must(template.ParseFiles("templates/admin/projects_table.html"))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -212,95 +136,130 @@ func RefreshProjects(w http.ResponseWriter, r *http.Request) {
 }
 
 func SaveProjects(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 	r.ParseMultipartForm(10 << 20)
 
-	rows, _ := db.Pool.Query(context.Background(), "SELECT id FROM projects")
-	defer rows.Close()
+	owned, err := loadUserProjects(r.Context(), uid, false)
+	if err != nil {
+		logger.Errorf("SaveProjects: select projects error (uid=%d): %v", uid, err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
 
-	for rows.Next() {
-		var id int
-		rows.Scan(&id)
+	// Сначала картинки: если какую-то не приняли, ничего не сохраняем
+	// и говорим почему, а не делаем вид, что всё прошло
+	images := map[int]string{}
+	for _, op := range owned {
+		imgURL, err := saveUploadedImage(r, fmt.Sprintf("image_%d", op.ID))
+		if err != nil {
+			logger.Errorf("SaveProjects: upload image error (id=%d): %v", op.ID, err)
+			msg, code := uploadErrorText(err)
+			http.Error(w, msg, code)
+			return
+		}
+		images[op.ID] = imgURL
+	}
+
+	for _, op := range owned {
+		id := op.ID
 
 		enabled := r.FormValue(fmt.Sprintf("enabled_%d", id)) == "on"
 		custom := r.FormValue(fmt.Sprintf("custom_%d", id))
+		pinned := r.FormValue(fmt.Sprintf("pinned_%d", id)) == "on"
+		group := strings.TrimSpace(r.FormValue(fmt.Sprintf("group_%d", id)))
 
-		imgURL := ""
-		file, hdr, err := r.FormFile(fmt.Sprintf("image_%d", id))
-		if err == nil {
-			defer file.Close()
-			fname := fmt.Sprintf("%d_%s", time.Now().Unix(), hdr.Filename)
-			dstPath := filepath.Join("static/uploads", fname)
-			dst, cerr := os.Create(dstPath)
-			if cerr != nil {
-				logger.Errorf("SaveProjects: create file error: %v", cerr)
-			} else {
-				if _, cpyErr := io.Copy(dst, file); cpyErr != nil {
-					logger.Errorf("SaveProjects: copy file error: %v", cpyErr)
-				}
-				dst.Close()
-			}
-			imgURL = "/uploads/" + fname
-		}
-
-		if imgURL != "" {
+		if imgURL := images[id]; imgURL != "" {
 			if _, err := db.Pool.Exec(context.Background(),
-				`UPDATE projects SET enabled=$1, custom_url=$2, image_url=$3 WHERE id=$4`,
-				enabled, custom, imgURL, id); err != nil {
+				`UPDATE projects SET enabled=$1, custom_url=$2, pinned=$3, group_name=$4, image_url=$5
+				  WHERE id=$6 AND user_id=$7`,
+				enabled, custom, pinned, group, imgURL, id, uid); err != nil {
 				logger.Errorf("SaveProjects: update with image error (id=%d): %v", id, err)
 			}
 		} else {
 			if _, err := db.Pool.Exec(context.Background(),
-				`UPDATE projects SET enabled=$1, custom_url=$2 WHERE id=$3`,
-				enabled, custom, id); err != nil {
+				`UPDATE projects SET enabled=$1, custom_url=$2, pinned=$3, group_name=$4 WHERE id=$5 AND user_id=$6`,
+				enabled, custom, pinned, group, id, uid); err != nil {
 				logger.Errorf("SaveProjects: update without image error (id=%d): %v", id, err)
 			}
 		}
 	}
 
 	// Возвращаем обновлённый кусок таблицы
-	rows2, err := db.Pool.Query(context.Background(), `
-        SELECT id, repo_name, title, description, image_url,
-               github_url, custom_url, enabled
-          FROM projects ORDER BY updated_at DESC`)
+	prjs, err := loadUserProjects(r.Context(), uid, false)
 	if err != nil {
 		logger.Errorf("SaveProjects: select projects error: %v", err)
 	}
-	defer rows2.Close()
-
-	var prjs []models.Project
-	for rows2.Next() {
-		var p models.Project
-		rows2.Scan(&p.ID, &p.RepoName, &p.Title, &p.Description,
-			&p.ImageURL, &p.GitHubURL, &p.CustomURL, &p.Enabled)
-		prjs = append(prjs, p)
-	}
 
 	tmpl := template.Must(template.ParseFiles("templates/admin/projects_table.html"))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	tmpl.ExecuteTemplate(w, "projects_table", prjs)
 }
 
+// saveUploadedImage проверяет и сохраняет картинку из поля формы (см.
+// пакет uploads), заносит в медиатеку и возвращает её адрес; "" — файл
+// не прислали
+func saveUploadedImage(r *http.Request, field string) (string, error) {
+	img, err := uploads.Default.FromRequest(r, field)
+	if err != nil || img == nil {
+		return "", err
+	}
+	if uid, ok := CurrentUserID(r); ok {
+		if err := recordMedia(r.Context(), uid, img); err != nil {
+			logger.Errorf("saveUploadedImage: record media error (uid=%d): %v", uid, err)
+		}
+	}
+	return img.URL, nil
+}
+
+// uploadErrorText — понятное пользователю сообщение об отклонённой картинке
+func uploadErrorText(err error) (string, int) {
+	switch {
+	case errors.Is(err, uploads.ErrTooLarge):
+		return fmt.Sprintf("Картинка слишком большая (не больше %d МБ)", uploads.Default.MaxBytes>>20), http.StatusRequestEntityTooLarge
+	case errors.Is(err, uploads.ErrUnsupported):
+		return "Поддерживаются только картинки JPEG, PNG, GIF и WebP", http.StatusBadRequest
+	}
+	return "Не удалось сохранить картинку", http.StatusInternalServerError
+}
+
 // ProjectsPage рендерит публичную страницу с включёнными проектами
+// пользователя: "/{slug}/projects". Старый "/projects" перенаправляет
+// залогиненного пользователя на его страницу. Сортировка и фильтры —
+// в параметрах запроса, см. ProjectFilter.
 func ProjectsPage(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Pool.Query(context.Background(), `
-        SELECT id, repo_name, title, description, image_url, github_url, custom_url
-          FROM projects
-         WHERE enabled = true
-      ORDER BY updated_at DESC`)
+	slug := mux.Vars(r)["slug"]
+	if slug == "" {
+		redirectToOwnSlug(w, r, "/projects")
+		return
+	}
+
+	s, err := settingsBySlug(r.Context(), slug)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	list, err := loadUserProjects(r.Context(), s.UserID, true)
 	if err != nil {
-		logger.Errorf("ProjectsPage: select enabled projects error: %v", err)
+		logger.Errorf("ProjectsPage: select enabled projects error (uid=%d): %v", s.UserID, err)
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
 
-	var list []models.Project
-	for rows.Next() {
-		var p models.Project
-		rows.Scan(&p.ID, &p.RepoName, &p.Title, &p.Description,
-			&p.ImageURL, &p.GitHubURL, &p.CustomURL)
-		list = append(list, p)
+	f := parseProjectFilter(r)
+	data := ProjectsViewData{
+		Slug:   s.Slug,
+		Filter: f,
+		Sorts:  projectSorts,
 	}
+	data.Languages, data.Topics, data.Licenses = projectFacets(list)
+	data.Projects = filterProjects(list, f)
+	sortProjects(data.Projects, f.Sort)
+	data.Sections = groupProjects(data.Projects)
 
 	tmpl := template.Must(template.ParseFiles(
 		"templates/header.html",
@@ -308,5 +267,112 @@ func ProjectsPage(w http.ResponseWriter, r *http.Request) {
 		"templates/footer.html",
 	))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	tmpl.ExecuteTemplate(w, "projects", list)
+	if err := tmpl.ExecuteTemplate(w, "projects", data); err != nil {
+		logger.Errorf("ProjectsPage: template render error: %v", err)
+	}
+}
+
+// redirectToOwnSlug отправляет залогиненного пользователя на его
+// публичную страницу "/{slug}"+suffix; гостю и пользователю без слага — 404
+func redirectToOwnSlug(w http.ResponseWriter, r *http.Request, suffix string) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var own string
+	_ = db.Pool.QueryRow(r.Context(),
+		"SELECT COALESCE(slug,'') FROM settings WHERE user_id=$1", uid).Scan(&own)
+	if own == "" {
+		http.NotFound(w, r)
+		return
+	}
+	http.Redirect(w, r, "/"+own+suffix, http.StatusSeeOther)
+}
+
+// loadPublicProject ищет включённый проект владельца по имени репозитория.
+// Одноимённые репозитории на разных хостингах различаются provider
+// ("manual" — ручной проект); без него предпочитаем GitHub.
+func loadPublicProject(ctx context.Context, uid int, repo, provider string) (models.Project, error) {
+	return scanProject(db.Pool.QueryRow(ctx, `
+        SELECT `+projectColumns+`
+          FROM projects
+         WHERE user_id = $1 AND repo_name = $2 AND enabled
+           AND ($3 = '' OR provider = $3 OR ($3 = 'manual' AND source = 'manual'))
+         ORDER BY (provider = $4) DESC, id
+         LIMIT 1`, uid, repo, provider, providers.DefaultProvider))
+}
+
+// loadProjectReadme — закэшированный README; pgx.ErrNoRows, если его нет
+func loadProjectReadme(ctx context.Context, projectID int) (models.ProjectReadme, error) {
+	var rd models.ProjectReadme
+	err := db.Pool.QueryRow(ctx, `
+        SELECT path, content, raw_root, web_root, updated_at FROM project_readmes WHERE project_id = $1`,
+		projectID).Scan(&rd.Path, &rd.Content, &rd.RawRoot, &rd.WebRoot, &rd.UpdatedAt)
+	return rd, err
+}
+
+// ProjectViewData — данные страницы проекта
+type ProjectViewData struct {
+	Slug    string
+	Project models.Project
+	// Readme — готовый HTML; пусто, если README нет
+	Readme template.HTML
+	// ReadmeURL — README в веб-интерфейсе хостинга
+	ReadmeURL string
+}
+
+// ProjectPage — страница проекта с README: "/{slug}/projects/{repo}".
+// Старый "/projects/{repo}" перенаправляет владельца на его страницу.
+func ProjectPage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	slug, repo := vars["slug"], vars["repo"]
+	if slug == "" {
+		suffix := "/projects/" + url.PathEscape(repo)
+		if r.URL.RawQuery != "" {
+			suffix += "?" + r.URL.RawQuery
+		}
+		redirectToOwnSlug(w, r, suffix)
+		return
+	}
+
+	s, err := settingsBySlug(r.Context(), slug)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	p, err := loadPublicProject(r.Context(), s.UserID, repo, r.URL.Query().Get("provider"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logger.Errorf("ProjectPage: select project %q error (uid=%d): %v", repo, s.UserID, err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+
+	data := ProjectViewData{Slug: s.Slug, Project: p}
+	rd, err := loadProjectReadme(r.Context(), p.ID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		logger.Errorf("ProjectPage: select README error (project=%d): %v", p.ID, err)
+	case rd.IsMarkdown():
+		data.Readme = renderMarkdown(rd.Content, &urlRewriter{file: rd.Path, rawRoot: rd.RawRoot, webRoot: rd.WebRoot})
+		data.ReadmeURL = rd.WebRoot + rd.Path
+	default:
+		data.Readme = template.HTML("<pre>" + template.HTMLEscapeString(rd.Content) + "</pre>")
+		data.ReadmeURL = rd.WebRoot + rd.Path
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"templates/header.html",
+		"templates/project.html",
+		"templates/footer.html",
+	))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(w, "project", data); err != nil {
+		logger.Errorf("ProjectPage: template render error: %v", err)
+	}
 }
@@ -0,0 +1,187 @@
+// handlers/projects_filter.go
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"Site/models"
+)
+
+// ProjectFilter — сортировка и фильтры публичной страницы проектов
+// (параметры запроса sort, lang, topic, license, archived)
+type ProjectFilter struct {
+	Sort     string
+	Language string
+	Topic    string
+	License  string
+	// Archived: "" — все, "hide" — без архивных, "only" — только архивные
+	Archived string
+}
+
+// ProjectSort — вариант сортировки для выпадающего списка
+type ProjectSort struct {
+	Key   string
+	Label string
+}
+
+// projectSorts — допустимые значения ?sort=, первое — по умолчанию
+var projectSorts = []ProjectSort{
+	{"position", "По порядку"},
+	{"updated", "Недавно обновлённые"},
+	{"pushed", "Последний коммит"},
+	{"stars", "Звёзды"},
+	{"forks", "Форки"},
+	{"name", "Название"},
+}
+
+// ProjectSection — раздел публичной страницы (группа проектов)
+type ProjectSection struct {
+	// Name — название группы; пусто — проекты без группы
+	Name     string
+	Projects []models.Project
+}
+
+// ProjectsViewData — данные публичной страницы проектов
+type ProjectsViewData struct {
+	// Slug — владелец страницы, для ссылок на страницы проектов
+	Slug     string
+	Projects []models.Project
+	// Sections — те же Projects, разбитые по группам
+	Sections []ProjectSection
+	Filter   ProjectFilter
+	Sorts    []ProjectSort
+	// Значения для фильтров — из всех включённых проектов, а не только показанных
+	Languages []string
+	Topics    []string
+	Licenses  []string
+}
+
+func parseProjectFilter(r *http.Request) ProjectFilter {
+	q := r.URL.Query()
+	f := ProjectFilter{
+		Sort:     projectSorts[0].Key,
+		Language: strings.TrimSpace(q.Get("lang")),
+		Topic:    strings.TrimSpace(q.Get("topic")),
+		License:  strings.TrimSpace(q.Get("license")),
+	}
+	for _, s := range projectSorts {
+		if q.Get("sort") == s.Key {
+			f.Sort = s.Key
+		}
+	}
+	switch a := q.Get("archived"); a {
+	case "hide", "only":
+		f.Archived = a
+	}
+	return f
+}
+
+// filterProjects оставляет проекты, подходящие под все заданные фильтры
+func filterProjects(list []models.Project, f ProjectFilter) []models.Project {
+	out := make([]models.Project, 0, len(list))
+	for _, p := range list {
+		switch {
+		case f.Language != "" && !strings.EqualFold(p.Language, f.Language):
+		case f.Topic != "" && !containsFold(p.Topics, f.Topic):
+		case f.License != "" && !strings.EqualFold(p.License, f.License):
+		case f.Archived == "hide" && p.Archived:
+		case f.Archived == "only" && !p.Archived:
+		default:
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// sortProjects сортирует по ключу из projectSorts; при равенстве — по названию
+func sortProjects(list []models.Project, key string) {
+	compare := func(a, b models.Project) int {
+		switch key {
+		case "position":
+			// Ручной порядок из админки, закреплённые — сверху
+			switch {
+			case a.Pinned != b.Pinned && a.Pinned:
+				return -1
+			case a.Pinned != b.Pinned:
+				return 1
+			case a.Position != b.Position:
+				return a.Position - b.Position
+			}
+			return a.ID - b.ID
+		case "stars":
+			return b.Stars - a.Stars
+		case "forks":
+			return b.Forks - a.Forks
+		case "pushed":
+			switch {
+			case a.PushedAt == nil && b.PushedAt == nil:
+				return 0
+			case a.PushedAt == nil:
+				return 1
+			case b.PushedAt == nil:
+				return -1
+			}
+			return b.PushedAt.Compare(*a.PushedAt)
+		case "updated":
+			return b.UpdatedAt.Compare(a.UpdatedAt)
+		}
+		return 0
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if c := compare(list[i], list[j]); c != 0 {
+			return c < 0
+		}
+		return strings.ToLower(list[i].Title) < strings.ToLower(list[j].Title)
+	})
+}
+
+// groupProjects раскладывает отсортированный список по группам. Разделы
+// идут в порядке первого появления, внутри раздела порядок сохраняется.
+func groupProjects(list []models.Project) []ProjectSection {
+	var sections []ProjectSection
+	index := map[string]int{}
+	for _, p := range list {
+		i, ok := index[p.Group]
+		if !ok {
+			i = len(sections)
+			index[p.Group] = i
+			sections = append(sections, ProjectSection{Name: p.Group})
+		}
+		sections[i].Projects = append(sections[i].Projects, p)
+	}
+	return sections
+}
+
+// projectFacets собирает уникальные языки, темы и лицензии для фильтров
+func projectFacets(list []models.Project) (langs, topics, licenses []string) {
+	seen := map[string]bool{}
+	add := func(dst *[]string, kind, v string) {
+		if v == "" || seen[kind+"\x00"+strings.ToLower(v)] {
+			return
+		}
+		seen[kind+"\x00"+strings.ToLower(v)] = true
+		*dst = append(*dst, v)
+	}
+	for _, p := range list {
+		add(&langs, "lang", p.Language)
+		add(&licenses, "license", p.License)
+		for _, t := range p.Topics {
+			add(&topics, "topic", t)
+		}
+	}
+	for _, s := range [][]string{langs, topics, licenses} {
+		sort.Slice(s, func(i, j int) bool { return strings.ToLower(s[i]) < strings.ToLower(s[j]) })
+	}
+	return langs, topics, licenses
+}
+
+func containsFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+// handlers/projects_order.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"Site/db"
+	"Site/logger"
+)
+
+// projectOrderRequest — новый порядок проектов из админки (drag-and-drop)
+type projectOrderRequest struct {
+	IDs []int `json:"ids"`
+}
+
+// writeJSON отдаёт v как JSON с кодом status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Errorf("writeJSON: encode error: %v", err)
+	}
+}
+
+// ReorderProjects — POST /admin/projects/order с телом {"ids": [3, 1, 2]}:
+// проекты получают позиции по порядку в списке. Чужие и неизвестные id
+// игнорируются; не попавшие в список проекты сохраняют свои позиции.
+func ReorderProjects(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	var req projectOrderRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "ожидается {\"ids\": [...]}"})
+		return
+	}
+	tag, err := db.Pool.Exec(r.Context(), `
+        UPDATE projects p
+           SET position = o.ord
+          FROM unnest($2::int[]) WITH ORDINALITY AS o(id, ord)
+         WHERE p.id = o.id AND p.user_id = $1`, uid, req.IDs)
+	if err != nil {
+		logger.Errorf("ReorderProjects: update error (uid=%d): %v", uid, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "DB error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"updated": tag.RowsAffected()})
+}
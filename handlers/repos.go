@@ -0,0 +1,127 @@
+// handlers/repos.go
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"Site/db"
+	"Site/logger"
+	"Site/providers"
+	"Site/uploads"
+)
+
+// gitProviders — настроенные хостинги кода (GitHub, GitLab, Gitea, Bitbucket)
+var gitProviders *providers.Registry
+
+// InitProviders собирает реестр провайдеров из окружения (GIT_PROVIDERS,
+// GITHUB_API_URL, GITHUB_PROXY). Вызывается из main после загрузки конфига.
+func InitProviders() error {
+	reg, err := providers.FromEnv()
+	if err != nil {
+		return err
+	}
+	gitProviders = reg
+	return nil
+}
+
+// upsertRepos сохраняет репозитории провайдера в проекты владельца uid
+func upsertRepos(ctx context.Context, uid int, provider string, repos []providers.Repo) error {
+	for _, repo := range repos {
+		// NULL в NOT NULL-колонки не пишем
+		topics := repo.Topics
+		if topics == nil {
+			topics = []string{}
+		}
+		langs := repo.Languages
+		if langs == nil {
+			langs = map[string]float64{}
+		}
+		// pushed_at — TIMESTAMP без зоны, как и остальные: через to_timestamp
+		var pushed *int64
+		if repo.PushedAt != nil {
+			v := repo.PushedAt.Unix()
+			pushed = &v
+		}
+		_, err := db.Pool.Exec(ctx, `
+            INSERT INTO projects(user_id,provider,repo_name,title,description,image_url,github_url,enabled,
+                                 stars,forks,language,languages,topics,homepage,license,pushed_at,archived,position)
+            VALUES($1,$2,$3,$4,$5,$6,$7,false,$8,$9,$10,$11,$12,$13,$14,to_timestamp($15)::timestamp,$16,
+                   -- новые проекты — в конец ручного порядка
+                   (SELECT COALESCE(MAX(position), 0) + 1 FROM projects WHERE user_id = $1))
+            ON CONFLICT(user_id, provider, repo_name) DO UPDATE SET
+              title       = EXCLUDED.title,
+              description = EXCLUDED.description,
+              -- свою загруженную картинку аватар владельца не перетирает
+              image_url   = CASE WHEN starts_with(projects.image_url, $17) THEN projects.image_url
+                                 ELSE EXCLUDED.image_url END,
+              github_url  = EXCLUDED.github_url,
+              stars       = EXCLUDED.stars,
+              forks       = EXCLUDED.forks,
+              language    = EXCLUDED.language,
+              languages   = EXCLUDED.languages,
+              topics      = EXCLUDED.topics,
+              homepage    = EXCLUDED.homepage,
+              license     = EXCLUDED.license,
+              pushed_at   = EXCLUDED.pushed_at,
+              archived    = EXCLUDED.archived,
+              updated_at  = NOW()
+            -- ручные проекты не трогаем никогда, импортированные —
+            -- только если что-то изменилось (чтобы не сдвигать updated_at)
+            WHERE projects.source = 'import'
+              AND (projects.title, projects.description, projects.image_url, projects.github_url,
+                   projects.stars, projects.forks, projects.language, projects.languages, projects.topics,
+                   projects.homepage, projects.license, projects.pushed_at, projects.archived)
+                  IS DISTINCT FROM
+                  (EXCLUDED.title, EXCLUDED.description,
+                   CASE WHEN starts_with(projects.image_url, $17) THEN projects.image_url
+                        ELSE EXCLUDED.image_url END,
+                   EXCLUDED.github_url,
+                   EXCLUDED.stars, EXCLUDED.forks, EXCLUDED.language, EXCLUDED.languages, EXCLUDED.topics,
+                   EXCLUDED.homepage, EXCLUDED.license, EXCLUDED.pushed_at, EXCLUDED.archived))
+        `, uid, provider, repo.Name, repo.Name, repo.Description, repo.AvatarURL, repo.HTMLURL,
+			repo.Stars, repo.Forks, repo.Language, langs, topics, repo.Homepage, repo.License, pushed, repo.Archived,
+			uploads.Default.Backend.URL(""))
+		if err != nil {
+			logger.Errorf("upsertRepos: project %s/%s error (uid=%d): %v", provider, repo.Name, uid, err)
+			return fmt.Errorf("DB upsert error for %s: %w", repo.Name, err)
+		}
+		if err := saveReadme(ctx, uid, provider, repo); err != nil {
+			logger.Errorf("upsertRepos: README %s/%s error (uid=%d): %v", provider, repo.Name, uid, err)
+			return fmt.Errorf("DB README error for %s: %w", repo.Name, err)
+		}
+	}
+	return nil
+}
+
+// saveReadme кэширует README проекта; если README пропал из репозитория —
+// удаляет его. repo.Readme == nil (не удалось загрузить) оставляет старый.
+func saveReadme(ctx context.Context, uid int, provider string, repo providers.Repo) error {
+	rd := repo.Readme
+	if rd == nil {
+		return nil
+	}
+	if rd.Content == "" {
+		_, err := db.Pool.Exec(ctx, `
+            DELETE FROM project_readmes
+             WHERE project_id = (SELECT id FROM projects
+                                  WHERE user_id=$1 AND provider=$2 AND repo_name=$3 AND source='import')`,
+			uid, provider, repo.Name)
+		return err
+	}
+	_, err := db.Pool.Exec(ctx, `
+        INSERT INTO project_readmes(project_id, path, content, raw_root, web_root)
+        SELECT id, $4, $5, $6, $7 FROM projects
+         WHERE user_id=$1 AND provider=$2 AND repo_name=$3 AND source='import'
+        ON CONFLICT(project_id) DO UPDATE SET
+          path       = EXCLUDED.path,
+          content    = EXCLUDED.content,
+          raw_root   = EXCLUDED.raw_root,
+          web_root   = EXCLUDED.web_root,
+          updated_at = NOW()
+        WHERE (project_readmes.path, project_readmes.content, project_readmes.raw_root, project_readmes.web_root)
+              IS DISTINCT FROM
+              (EXCLUDED.path, EXCLUDED.content, EXCLUDED.raw_root, EXCLUDED.web_root)`,
+		uid, provider, repo.Name, rd.Path, rd.Content, rd.RawRoot, rd.WebRoot)
+	return err
+}
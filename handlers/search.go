@@ -0,0 +1,158 @@
+// handlers/search.go
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"Site/db"
+	"Site/logger"
+	"Site/models"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// searchLimit — сколько совпадений каждого вида показывает страница поиска
+const searchLimit = 30
+
+// Метки начала и конца совпадения во фрагментах ts_headline: символы из
+// области частного использования, в обычном тексте их не бывает
+const (
+	hlStart = "\ue000"
+	hlStop  = "\ue001"
+)
+
+// tsQuery — запрос из строки поиска ($n) в обеих конфигурациях, как и
+// столбцы search (см. миграцию 0018_search). websearch_to_tsquery понимает
+// "фразы в кавычках", OR и -исключения и не падает на произвольном вводе.
+func tsQuery(n int) string {
+	return fmt.Sprintf("(websearch_to_tsquery('russian', $%[1]d) || websearch_to_tsquery('english', $%[1]d))", n)
+}
+
+// tsHeadline — фрагмент column с отмеченными совпадениями запроса $n
+func tsHeadline(column string, n int) string {
+	return fmt.Sprintf(`ts_headline('russian', %s, %s, 'StartSel="%s", StopSel="%s", MaxWords=30, MinWords=12, MaxFragments=2')`,
+		column, tsQuery(n), hlStart, hlStop)
+}
+
+// snippetHTML экранирует фрагмент и превращает метки в <mark>
+func snippetHTML(s string) template.HTML {
+	s = template.HTMLEscapeString(s)
+	s = strings.ReplaceAll(s, hlStart, "<mark>")
+	s = strings.ReplaceAll(s, hlStop, "</mark>")
+	return template.HTML(s)
+}
+
+// withExtra дочитывает в строку результата дополнительные столбцы после
+// тех, что сканирует scanPost/scanProject
+type withExtra struct {
+	pgx.Row
+	extra []any
+}
+
+func (r withExtra) Scan(dest ...any) error { return r.Row.Scan(append(dest, r.extra...)...) }
+
+// PostHit — найденный пост с фрагментом текста
+type PostHit struct {
+	Post    models.Post
+	Snippet template.HTML
+}
+
+// ProjectHit — найденный проект с фрагментом описания
+type ProjectHit struct {
+	Project models.Project
+	Snippet template.HTML
+}
+
+// searchPublishedPosts — опубликованные посты автора по запросу, самые
+// релевантные первыми
+func searchPublishedPosts(ctx context.Context, uid int, q string) ([]PostHit, error) {
+	rows, err := db.Pool.Query(ctx, `
+        SELECT `+postColumns+`, `+tsHeadline("text", 2)+`
+          FROM post
+         WHERE user_id = $1 AND status = 'published' AND search @@ `+tsQuery(2)+`
+         ORDER BY ts_rank_cd(search, `+tsQuery(2)+`) DESC, published_at DESC
+         LIMIT $3`, uid, q, searchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var hits []PostHit
+	for rows.Next() {
+		var snippet string
+		p, err := scanPost(withExtra{rows, []any{&snippet}})
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, PostHit{Post: p, Snippet: snippetHTML(snippet)})
+	}
+	return hits, rows.Err()
+}
+
+// searchEnabledProjects — опубликованные проекты автора по запросу
+func searchEnabledProjects(ctx context.Context, uid int, q string) ([]ProjectHit, error) {
+	rows, err := db.Pool.Query(ctx, `
+        SELECT `+projectColumns+`, `+tsHeadline("COALESCE(description, '')", 2)+`
+          FROM projects
+         WHERE user_id = $1 AND enabled AND search @@ `+tsQuery(2)+`
+         ORDER BY ts_rank_cd(search, `+tsQuery(2)+`) DESC, pinned DESC, position, id
+         LIMIT $3`, uid, q, searchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var hits []ProjectHit
+	for rows.Next() {
+		var snippet string
+		p, err := scanProject(withExtra{rows, []any{&snippet}})
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, ProjectHit{Project: p, Snippet: snippetHTML(snippet)})
+	}
+	return hits, rows.Err()
+}
+
+// SearchViewData — данные страницы поиска по профилю
+type SearchViewData struct {
+	Slug     string
+	Query    string
+	Posts    []PostHit
+	Projects []ProjectHit
+}
+
+// ProfileSearch — поиск по постам и проектам автора: "/{slug}/search?q="
+func ProfileSearch(w http.ResponseWriter, r *http.Request) {
+	s, err := settingsBySlug(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	data := SearchViewData{Slug: s.Slug, Query: strings.TrimSpace(r.URL.Query().Get("q"))}
+	if data.Query != "" {
+		if data.Posts, err = searchPublishedPosts(r.Context(), s.UserID, data.Query); err != nil {
+			logger.Errorf("ProfileSearch: posts query error (uid=%d): %v", s.UserID, err)
+			http.Error(w, "DB error", http.StatusInternalServerError)
+			return
+		}
+		if data.Projects, err = searchEnabledProjects(r.Context(), s.UserID, data.Query); err != nil {
+			logger.Errorf("ProfileSearch: projects query error (uid=%d): %v", s.UserID, err)
+			http.Error(w, "DB error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"templates/header.html",
+		"templates/search.html",
+		"templates/footer.html",
+	))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(w, "search", data); err != nil {
+		logger.Errorf("ProfileSearch: template render error: %v", err)
+	}
+}
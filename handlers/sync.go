@@ -0,0 +1,348 @@
+// handlers/sync.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"Site/db"
+	"Site/logger"
+	"Site/models"
+	"Site/providers"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Статусы источника и запуска синхронизации
+const (
+	syncStatusRunning = "running"
+	syncStatusOK      = "ok"
+	syncStatusError   = "error"
+)
+
+// staleSyncAfter — через сколько «зависший» running можно перезапустить
+// (например, инстанс упал посреди синхронизации)
+const staleSyncAfter = 15 * time.Minute
+
+// manualSyncTimeout — сколько синхронизация из админки может держать
+// запрос: медленный API не должен вешать страницу. Не успевший источник
+// получает статус error, дальше его подхватит воркер по расписанию.
+const manualSyncTimeout = 2 * time.Minute
+
+// scheduledSyncTimeout — предел одного источника в воркере: зависший API
+// не должен задерживать остальные источники и следующие тики. Меньше
+// staleSyncAfter, чтобы источник не считался зависшим, пока его ещё ждут.
+const scheduledSyncTimeout = 10 * time.Minute
+
+// errSyncBusy — источник уже синхронизируется
+var errSyncBusy = errors.New("синхронизация уже выполняется")
+
+// SyncWorker периодически пересинхронизирует все сохранённые источники
+// проектов. Интервал источника берётся из project_sources.interval_minutes,
+// а если он не задан — из DefaultInterval.
+type SyncWorker struct {
+	Providers       *providers.Registry
+	Tick            time.Duration
+	DefaultInterval time.Duration
+}
+
+// NewSyncWorker создаёт воркер с настройками из окружения:
+//
+//	SYNC_INTERVAL -> интервал по умолчанию (Go duration, "0"/"off" — только вручную)
+func NewSyncWorker() *SyncWorker {
+	w := &SyncWorker{
+		Providers:       gitProviders,
+		Tick:            time.Minute,
+		DefaultInterval: 6 * time.Hour,
+	}
+	switch v := strings.TrimSpace(strings.ToLower(os.Getenv("SYNC_INTERVAL"))); v {
+	case "":
+	case "0", "off", "none":
+		w.DefaultInterval = 0
+	default:
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			w.DefaultInterval = d
+		} else {
+			logger.Errorf("SyncWorker: bad SYNC_INTERVAL %q, using %s", v, w.DefaultInterval)
+		}
+	}
+	return w
+}
+
+// Run крутится до отмены ctx, на каждом тике синхронизируя созревшие источники.
+func (w *SyncWorker) Run(ctx context.Context) {
+	logger.Infof("SyncWorker: started (tick %s, default interval %s)", w.Tick, w.DefaultInterval)
+	t := time.NewTicker(w.Tick)
+	defer t.Stop()
+	for {
+		w.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunOnce синхронизирует все источники, у которых подошёл срок,
+// и возвращает число успешно синхронизированных.
+func (w *SyncWorker) RunOnce(ctx context.Context) int {
+	due, err := dueProjectSources(ctx, int(w.DefaultInterval/time.Minute))
+	if err != nil {
+		logger.Errorf("SyncWorker: select due sources error: %v", err)
+		return 0
+	}
+	ok := 0
+	for _, src := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if w.syncSource(ctx, src) {
+			ok++
+		}
+	}
+	return ok
+}
+
+// syncSource синхронизирует один источник с пределом scheduledSyncTimeout
+func (w *SyncWorker) syncSource(ctx context.Context, src models.ProjectSource) bool {
+	ctx, cancel := context.WithTimeout(ctx, scheduledSyncTimeout)
+	defer cancel()
+	if _, err := syncProjectSource(ctx, w.Providers, src, "schedule"); err != nil {
+		if !errors.Is(err, errSyncBusy) {
+			logger.Errorf("SyncWorker: source %d (%s) error: %v", src.ID, src.Source, err)
+		}
+		return false
+	}
+	return true
+}
+
+const projectSourceColumns = `id, user_id, provider, source, interval_minutes, last_sync_at, last_status, last_error,
+        include_forks, include_archived, include_private`
+
+func scanProjectSource(row pgx.Row) (models.ProjectSource, error) {
+	var s models.ProjectSource
+	err := row.Scan(&s.ID, &s.UserID, &s.Provider, &s.Source, &s.IntervalMinutes, &s.LastSyncAt, &s.LastStatus, &s.LastError,
+		&s.IncludeForks, &s.IncludeArchived, &s.IncludePrivate)
+	return s, err
+}
+
+func queryProjectSources(ctx context.Context, sql string, args ...any) ([]models.ProjectSource, error) {
+	rows, err := db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.ProjectSource
+	for rows.Next() {
+		s, err := scanProjectSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// dueProjectSources — источники, которые пора синхронизировать
+func dueProjectSources(ctx context.Context, defaultMinutes int) ([]models.ProjectSource, error) {
+	return queryProjectSources(ctx, `
+        SELECT `+projectSourceColumns+`
+          FROM project_sources
+         WHERE COALESCE(interval_minutes, $1) > 0
+           AND (last_sync_at IS NULL
+                OR last_sync_at + make_interval(mins => COALESCE(interval_minutes, $1)) <= NOW())
+         ORDER BY last_sync_at NULLS FIRST, id`, defaultMinutes)
+}
+
+// loadUserProjectSources — источники владельца для админки
+func loadUserProjectSources(ctx context.Context, uid int) ([]models.ProjectSource, error) {
+	return queryProjectSources(ctx, `
+        SELECT `+projectSourceColumns+`
+          FROM project_sources
+         WHERE user_id = $1
+         ORDER BY provider, source`, uid)
+}
+
+// loadUserSyncRuns — последние запуски синхронизации владельца
+func loadUserSyncRuns(ctx context.Context, uid, limit int) ([]models.SyncRun, error) {
+	rows, err := db.Pool.Query(ctx, `
+        SELECT r.id, r.source_id, s.provider, s.source, r.trigger, r.started_at, r.finished_at,
+               r.status, r.error, r.repos_count
+          FROM project_sync_runs r
+          JOIN project_sources s ON s.id = r.source_id
+         WHERE s.user_id = $1
+         ORDER BY r.started_at DESC, r.id DESC
+         LIMIT $2`, uid, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.SyncRun
+	for rows.Next() {
+		var sr models.SyncRun
+		if err := rows.Scan(&sr.ID, &sr.SourceID, &sr.Provider, &sr.Source, &sr.Trigger, &sr.StartedAt, &sr.FinishedAt,
+			&sr.Status, &sr.Error, &sr.ReposCount); err != nil {
+			return nil, err
+		}
+		list = append(list, sr)
+	}
+	return list, rows.Err()
+}
+
+// saveProjectSource запоминает источник владельца с фильтрами
+// (повторное добавление того же источника обновляет фильтры)
+func saveProjectSource(ctx context.Context, uid int, provider, source string, opts providers.ListOptions) (models.ProjectSource, error) {
+	return scanProjectSource(db.Pool.QueryRow(ctx, `
+        INSERT INTO project_sources(user_id, provider, source, include_forks, include_archived, include_private)
+        VALUES($1,$2,$3,$4,$5,$6)
+        ON CONFLICT(user_id, provider, source) DO UPDATE SET
+          include_forks    = EXCLUDED.include_forks,
+          include_archived = EXCLUDED.include_archived,
+          include_private  = EXCLUDED.include_private
+        RETURNING `+projectSourceColumns, uid, provider, source, opts.Forks, opts.Archived, opts.Private))
+}
+
+// syncProjectSource загружает репозитории источника и сохраняет их
+// в проекты владельца, записывая статус и историю. Параллельный запуск
+// того же источника (другой инстанс или двойной клик) вернёт errSyncBusy.
+func syncProjectSource(ctx context.Context, reg *providers.Registry, src models.ProjectSource, trigger string) (int, error) {
+	// Захватываем источник: ставим running, если он ещё не выполняется
+	tag, err := db.Pool.Exec(ctx, `
+        UPDATE project_sources
+           SET last_status = $2, last_sync_at = NOW()
+         WHERE id = $1
+           AND (last_status <> $2 OR last_sync_at < NOW() - make_interval(secs => $3))`,
+		src.ID, syncStatusRunning, staleSyncAfter.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, errSyncBusy
+	}
+
+	var runID int
+	if err := db.Pool.QueryRow(ctx,
+		`INSERT INTO project_sync_runs(source_id, trigger) VALUES($1,$2) RETURNING id`,
+		src.ID, trigger).Scan(&runID); err != nil {
+		logger.Errorf("syncProjectSource: insert run error (source=%d): %v", src.ID, err)
+	}
+
+	var repos []providers.Repo
+	if p := reg.Get(src.Provider); p == nil {
+		err = fmt.Errorf("провайдер %s не настроен", src.Provider)
+	} else {
+		opts := providers.ListOptions{Forks: src.IncludeForks, Archived: src.IncludeArchived, Private: src.IncludePrivate}
+		if opts.Private && !isAdminUser(ctx, src.UserID) {
+			// Владелец больше не admin — приватные через общий пул не берём
+			opts.Private = false
+		}
+		repos, err = providers.Fetch(ctx, p, src.Source, opts)
+		if err == nil {
+			err = upsertRepos(ctx, src.UserID, p.ID(), repos)
+		}
+	}
+
+	status, errText, count := syncStatusOK, "", len(repos)
+	if err != nil {
+		status, errText, count = syncStatusError, err.Error(), 0
+	}
+	// Итог пишем даже если ctx уже отменён, иначе источник останется running
+	fin := context.Background()
+	if _, uerr := db.Pool.Exec(fin, `
+        UPDATE project_sources SET last_status=$2, last_error=$3, last_sync_at=NOW() WHERE id=$1`,
+		src.ID, status, errText); uerr != nil {
+		logger.Errorf("syncProjectSource: update source error (source=%d): %v", src.ID, uerr)
+	}
+	if runID > 0 {
+		if _, uerr := db.Pool.Exec(fin, `
+            UPDATE project_sync_runs SET status=$2, error=$3, repos_count=$4, finished_at=NOW() WHERE id=$1`,
+			runID, status, errText, count); uerr != nil {
+			logger.Errorf("syncProjectSource: update run error (run=%d): %v", runID, uerr)
+		}
+	}
+	return count, err
+}
+
+// isAdminUser — у пользователя роль admin (по базе, а не по куке:
+// воркер работает без запроса)
+func isAdminUser(ctx context.Context, uid int) bool {
+	var role string
+	_ = db.Pool.QueryRow(ctx, `SELECT COALESCE(role, '') FROM users WHERE id=$1`, uid).Scan(&role)
+	return role == "admin"
+}
+
+// userProjectSource загружает источник по id, только если он принадлежит uid
+func userProjectSource(ctx context.Context, uid, id int) (models.ProjectSource, error) {
+	return scanProjectSource(db.Pool.QueryRow(ctx, `
+        SELECT `+projectSourceColumns+` FROM project_sources WHERE id=$1 AND user_id=$2`, id, uid))
+}
+
+// SyncSourceNow — кнопка «Синхронизировать сейчас» в админке
+func SyncSourceNow(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	src, err := userProjectSource(r.Context(), uid, id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), manualSyncTimeout)
+	defer cancel()
+	if _, err := syncProjectSource(ctx, gitProviders, src, "manual"); err != nil {
+		// Ошибка уже записана в историю источника — просто показываем вкладку
+		logger.Errorf("SyncSourceNow: source %d (%s) error: %v", src.ID, src.Source, err)
+	}
+	http.Redirect(w, r, "/admin?tab=projects", 303)
+}
+
+// ScheduleSource — задаёт интервал автосинхронизации источника
+// (пусто — по умолчанию, 0 — только вручную)
+func ScheduleSource(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	var interval *int
+	if v := strings.TrimSpace(r.FormValue("interval_minutes")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "Интервал должен быть неотрицательным числом минут", http.StatusBadRequest)
+			return
+		}
+		interval = &n
+	}
+	if _, err := db.Pool.Exec(r.Context(),
+		`UPDATE project_sources SET interval_minutes=$1 WHERE id=$2 AND user_id=$3`,
+		interval, id, uid); err != nil {
+		logger.Errorf("ScheduleSource: update error (id=%d, uid=%d): %v", id, uid, err)
+	}
+	http.Redirect(w, r, "/admin?tab=projects", 303)
+}
+
+// DeleteSource — убирает источник из автосинхронизации (проекты остаются)
+func DeleteSource(w http.ResponseWriter, r *http.Request) {
+	uid, ok := CurrentUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	if _, err := db.Pool.Exec(r.Context(),
+		`DELETE FROM project_sources WHERE id=$1 AND user_id=$2`, id, uid); err != nil {
+		logger.Errorf("DeleteSource: delete error (id=%d, uid=%d): %v", id, uid, err)
+	}
+	http.Redirect(w, r, "/admin?tab=projects", 303)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"Site/db"
+	"Site/providers"
+)
+
+// fakeGitHub — GitHub API с двумя страницами репозиториев аккаунта octo,
+// приватным репозиторием octo/secret и аккаунтом broken, на котором API
+// падает. Ответы со страницами отдают ETag и на совпадающий If-None-Match
+// отвечают 304.
+type fakeGitHub struct {
+	srv         *httptest.Server
+	notModified atomic.Int32
+	// readmes — сколько раз запрашивали README
+	readmes atomic.Int32
+}
+
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	f := &fakeGitHub{}
+	mux := http.NewServeMux()
+	conditional := func(w http.ResponseWriter, r *http.Request, etag, body string) {
+		if r.Header.Get("If-None-Match") == etag {
+			f.notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}
+	mux.HandleFunc("/users/octo", func(w http.ResponseWriter, r *http.Request) {
+		conditional(w, r, `"user"`, `{"login":"octo","type":"User"}`)
+	})
+	mux.HandleFunc("/users/octo/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			conditional(w, r, `"page2"`, `[{"name":"two","full_name":"octo/two","owner":{"login":"octo"}}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/users/octo/repos?type=owner&per_page=100&page=2>; rel="next"`, f.srv.URL))
+		conditional(w, r, `"page1"`, `[{"name":"one","full_name":"octo/one","owner":{"login":"octo"}}]`)
+	})
+	mux.HandleFunc("/repos/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/languages"):
+			fmt.Fprint(w, `{"Go": 100}`)
+		case strings.HasSuffix(r.URL.Path, "/readme"):
+			f.readmes.Add(1)
+			fmt.Fprint(w, `{"path":"README.md","content":"c2VjcmV0","encoding":"base64"}`)
+		case r.URL.Path == "/repos/octo/secret":
+			fmt.Fprint(w, `{"name":"secret","full_name":"octo/secret","private":true,"owner":{"login":"octo"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	mux.HandleFunc("/users/broken", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"boom"}`, http.StatusInternalServerError)
+	})
+	f.srv = httptest.NewServer(mux)
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+// testDB подключает тесты к настоящей базе TEST_DATABASE_URL (её таблицы
+// будут мигрированы); без неё тест пропускается
+func testDB(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	t.Setenv("DATABASE_URL", dsn)
+	db.InitPool()
+	t.Cleanup(db.ClosePool)
+}
+
+// testUser создаёт пользователя, который удаляется в конце теста
+// (вместе с его постами, проектами и источниками)
+func testUser(t *testing.T) int {
+	var uid int
+	if err := db.Pool.QueryRow(context.Background(),
+		`INSERT INTO users(username, password_hash, role) VALUES($1, '', 'user') RETURNING id`,
+		fmt.Sprintf("test-%s-%d", t.Name(), os.Getpid())).Scan(&uid); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), `DELETE FROM users WHERE id=$1`, uid)
+	})
+	return uid
+}
+
+// TestSyncProjectSource гоняет синхронизацию против фейкового GitHub
+// на настоящей базе (см. testDB; тестовый пользователь и кэш ответов
+// удаляются в конце).
+func TestSyncProjectSource(t *testing.T) {
+	testDB(t)
+	ctx := context.Background()
+
+	fake := newFakeGitHub(t)
+	p, err := providers.New("github", "https://github.test",
+		providers.WithAPI(fake.srv.URL), providers.WithHTTPClient(fake.srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := providers.NewRegistry(p)
+
+	uid := testUser(t)
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), `DELETE FROM api_http_cache WHERE url LIKE $1 || '%'`, fake.srv.URL)
+	})
+
+	t.Run("pagination and 304", func(t *testing.T) {
+		src, err := saveProjectSource(ctx, uid, p.ID(), "octo", providers.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for run := 1; run <= 2; run++ {
+			n, err := syncProjectSource(ctx, reg, src, "manual")
+			if err != nil {
+				t.Fatalf("run %d: %v", run, err)
+			}
+			if n != 2 {
+				t.Fatalf("run %d: synced %d repos, want 2 (both pages)", run, n)
+			}
+		}
+		// Второй запуск: аккаунт и обе страницы пришли из кэша по 304
+		if got := fake.notModified.Load(); got != 3 {
+			t.Errorf("304 responses = %d, want 3", got)
+		}
+		var names []string
+		rows, err := db.Pool.Query(ctx, `SELECT repo_name FROM projects WHERE user_id=$1 ORDER BY repo_name`, uid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rows.Next() {
+			var n string
+			rows.Scan(&n)
+			names = append(names, n)
+		}
+		if strings.Join(names, ",") != "one,two" {
+			t.Errorf("projects = %v, want [one two]", names)
+		}
+	})
+
+	t.Run("private repo needs private import", func(t *testing.T) {
+		// Не admin: приватные не берутся даже с включённой галочкой
+		for _, opts := range []providers.ListOptions{{}, {Private: true}} {
+			src, err := saveProjectSource(ctx, uid, p.ID(), "octo/secret", opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := syncProjectSource(ctx, reg, src, "manual"); !providers.IsNotFound(err) {
+				t.Errorf("Private=%v: err = %v, want not found", opts.Private, err)
+			}
+		}
+		var n int
+		if err := db.Pool.QueryRow(ctx,
+			`SELECT COUNT(*) FROM projects WHERE user_id=$1 AND repo_name='secret'`, uid).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("private repo was imported")
+		}
+		if got := fake.readmes.Load(); got != 0 {
+			t.Errorf("README of private repo requested %d time(s)", got)
+		}
+	})
+
+	t.Run("error is stored in source status", func(t *testing.T) {
+		src, err := saveProjectSource(ctx, uid, p.ID(), "broken", providers.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := syncProjectSource(ctx, reg, src, "manual"); err == nil {
+			t.Fatal("want error from failing API")
+		}
+		got, err := userProjectSource(ctx, uid, src.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.LastStatus != syncStatusError || !strings.Contains(got.LastError, "500") {
+			t.Errorf("status = %q, error = %q; want %q with API status", got.LastStatus, got.LastError, syncStatusError)
+		}
+		var runStatus string
+		if err := db.Pool.QueryRow(ctx,
+			`SELECT status FROM project_sync_runs WHERE source_id=$1 ORDER BY id DESC LIMIT 1`, src.ID).Scan(&runStatus); err != nil {
+			t.Fatal(err)
+		}
+		if runStatus != syncStatusError {
+			t.Errorf("run status = %q, want %q", runStatus, syncStatusError)
+		}
+	})
+}
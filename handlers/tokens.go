@@ -0,0 +1,82 @@
+// handlers/tokens.go
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Site/logger"
+	"Site/models"
+	"Site/tokenpool"
+)
+
+// TokensViewData — контекст для tokens.html
+type TokensViewData struct {
+	Tokens    []models.GitHubToken
+	Providers []string
+	Now       time.Time
+	Error     string
+}
+
+// TokensPage — состояние пулов токенов всех провайдеров (только для роли admin)
+func TokensPage(w http.ResponseWriter, r *http.Request) {
+	renderTokensPage(w, r, "")
+}
+
+func renderTokensPage(w http.ResponseWriter, r *http.Request, msg string) {
+	list, err := tokenpool.List(r.Context())
+	if err != nil {
+		logger.Errorf("TokensPage: list tokens error: %v", err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	tmpl := template.Must(template.ParseFiles("templates/admin/tokens.html"))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	var ids []string
+	for _, p := range gitProviders.List() {
+		ids = append(ids, p.ID())
+	}
+	tmpl.ExecuteTemplate(w, "tokens", TokensViewData{Tokens: list, Providers: ids, Now: time.Now(), Error: msg})
+}
+
+// TokenAdd — добавить токен в пул выбранного провайдера
+func TokenAdd(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.FormValue("token"))
+	if token == "" {
+		renderTokensPage(w, r, "Укажите токен")
+		return
+	}
+	p := gitProviders.Get(r.FormValue("provider"))
+	if p == nil {
+		renderTokensPage(w, r, "Неизвестный провайдер")
+		return
+	}
+	if err := p.Tokens().Add(r.Context(), token); err != nil {
+		logger.Errorf("TokenAdd: insert error: %v", err)
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/admin/tokens", 303)
+}
+
+// TokenToggle — включить/выключить токен (включение сбрасывает ошибки и паузу)
+func TokenToggle(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	enabled := r.FormValue("enabled") == "1"
+	if err := tokenpool.SetEnabled(r.Context(), id, enabled); err != nil {
+		logger.Errorf("TokenToggle: update error (id=%d): %v", id, err)
+	}
+	http.Redirect(w, r, "/admin/tokens", 303)
+}
+
+// TokenDelete — удалить токен из пула
+func TokenDelete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	if err := tokenpool.Delete(r.Context(), id); err != nil {
+		logger.Errorf("TokenDelete: delete error (id=%d): %v", id, err)
+	}
+	http.Redirect(w, r, "/admin/tokens", 303)
+}
@@ -0,0 +1,119 @@
+// handlers/uploads_gc.go
+package handlers
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"Site/db"
+	"Site/logger"
+	"Site/uploads"
+)
+
+// UploadGCWorker периодически удаляет загруженные картинки, на которые
+// больше не ссылаются ни проекты, ни настройки профиля, ни медиатека,
+// ни посты с их ревизиями
+type UploadGCWorker struct {
+	Store    *uploads.Store
+	Interval time.Duration
+	// Grace — сколько живёт свежий файл без ссылки (форма могла ещё не сохраниться)
+	Grace time.Duration
+}
+
+// NewUploadGCWorker создаёт воркер с настройками из окружения:
+//
+//	UPLOAD_GC -> интервал уборки (Go duration, "0"/"off" — не убирать)
+func NewUploadGCWorker() *UploadGCWorker {
+	w := &UploadGCWorker{
+		Store:    uploads.Default,
+		Interval: 24 * time.Hour,
+		Grace:    24 * time.Hour,
+	}
+	switch v := strings.TrimSpace(strings.ToLower(os.Getenv("UPLOAD_GC"))); v {
+	case "":
+	case "0", "off", "none":
+		w.Interval = 0
+	default:
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			w.Interval = d
+		} else {
+			logger.Errorf("UploadGCWorker: bad UPLOAD_GC %q, using %s", v, w.Interval)
+		}
+	}
+	return w
+}
+
+// Run убирает сироты раз в Interval до отмены ctx
+func (w *UploadGCWorker) Run(ctx context.Context) {
+	if w.Interval <= 0 {
+		logger.Infof("UploadGCWorker: disabled")
+		return
+	}
+	logger.Infof("UploadGCWorker: started (interval %s, grace %s)", w.Interval, w.Grace)
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+	for {
+		n, err := w.Store.GC(ctx, uploadReferences, w.Grace)
+		if err != nil {
+			logger.Errorf("UploadGCWorker: %v", err)
+		} else if n > 0 {
+			logger.Infof("UploadGCWorker: removed %d orphaned files", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// uploadReferences — все адреса картинок, которые ещё показываются на сайте
+// или могут вернуться: проекты, фон, медиатека, а также картинки в тексте
+// постов и их ревизий (восстановленная ревизия не должна остаться без них)
+func uploadReferences(ctx context.Context) ([]string, error) {
+	base := uploads.Default.Backend.URL("")
+	rows, err := db.Pool.Query(ctx, `
+        SELECT image_url FROM projects WHERE starts_with(image_url, $1)
+        UNION
+        SELECT home_bg_url FROM settings WHERE starts_with(home_bg_url, $1)
+        UNION
+        SELECT url FROM media`, base)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var urls []string
+	for rows.Next() {
+		var u string
+		if err := rows.Scan(&u); err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	texts, err := db.Pool.Query(ctx, `
+        SELECT text FROM post WHERE strpos(text, $1) > 0
+        UNION ALL
+        SELECT summary FROM post WHERE strpos(summary, $1) > 0
+        UNION ALL
+        SELECT text FROM post_revisions WHERE strpos(text, $1) > 0`, base)
+	if err != nil {
+		return nil, err
+	}
+	defer texts.Close()
+	embedded := regexp.MustCompile(regexp.QuoteMeta(base) + `[^\s"'()<>\[\]]+`)
+	for texts.Next() {
+		var t string
+		if err := texts.Scan(&t); err != nil {
+			return nil, err
+		}
+		urls = append(urls, embedded.FindAllString(t, -1)...)
+	}
+	return urls, texts.Err()
+}
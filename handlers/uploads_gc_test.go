@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Site/db"
+	"Site/storage"
+	"Site/uploads"
+)
+
+// TestUploadGCReferences: картинка, которая осталась только в анонсе поста
+// или только в старой ревизии, уборкой не удаляется, а файл без ссылок
+// старше grace — удаляется. Нужна база, см. testDB.
+func TestUploadGCReferences(t *testing.T) {
+	testDB(t)
+	ctx := context.Background()
+	uid := testUser(t)
+
+	dir := t.TempDir()
+	store := uploads.New(storage.NewLocal(dir, uploads.URLPrefix))
+	prev := uploads.Default
+	uploads.Default = store
+	t.Cleanup(func() { uploads.Default = prev })
+
+	const (
+		inSummary  = "11111111111111111111111111111111.png"
+		inRevision = "22222222222222222222222222222222.jpg"
+		orphan     = "33333333333333333333333333333333.png"
+	)
+	old := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{inSummary, inRevision, orphan} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var postID int
+	if err := db.Pool.QueryRow(ctx, `
+        INSERT INTO post(label, text, user_id, slug, summary)
+        VALUES('gc', 'без картинок', $1, 'gc-test', $2) RETURNING id`,
+		uid, "Анонс ![](/uploads/"+inSummary+")").Scan(&postID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Pool.Exec(ctx, `
+        INSERT INTO post_revisions(post_id, author_id, label, text) VALUES($1, $2, 'gc', $3)`,
+		postID, uid, `Было: <img src="/uploads/`+inRevision+`">`); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := store.GC(ctx, uploadReferences, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("removed %d file(s), want 1", n)
+	}
+	for name, kept := range map[string]bool{inSummary: true, inRevision: true, orphan: false} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != kept {
+			t.Errorf("%s: exists = %v, want %v", name, exists, kept)
+		}
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"Site/db"
 	"Site/handlers"
 	"Site/logger"
+	"Site/storage"
+	"Site/uploads"
+	"context"
 	"log"
 	"net/http"
 	"os"
@@ -19,21 +22,79 @@ func main() {
 	config.Load()
 	// Инициализация логгера из переменных окружения
 	logger.Init()
+
+	// Подкоманда миграций: ./Site migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+	// Статическая выгрузка профиля: ./Site export <slug> <каталог|файл.zip>
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(runExport(os.Args[2:]))
+	}
+
 	// Инициализация и отложенное закрытие пула соединений с БД
 	db.InitPool()
 	defer db.ClosePool()
+	// Слаги постам, созданным до постоянных адресов
+	handlers.BackfillPostSlugs(context.Background())
+
+	// Хранилище загрузок: диск или S3 (STORAGE)
+	if err := uploads.Init(); err != nil {
+		log.Fatalf("uploads: %v", err)
+	}
+
+	// Хостинги кода для импорта проектов (GIT_PROVIDERS)
+	if err := handlers.InitProviders(); err != nil {
+		log.Fatalf("providers: %v", err)
+	}
+
+	// Политика санитайзера Markdown (MARKDOWN_POLICY)
+	if err := handlers.InitMarkdown(); err != nil {
+		log.Fatalf("markdown: %v", err)
+	}
+
+	// Адрес сайта для абсолютных ссылок в лентах (SITE_URL)
+	if err := handlers.InitSiteURL(); err != nil {
+		log.Fatalf("site url: %v", err)
+	}
+
+	// Фоновая синхронизация проектов из сохранённых источников
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handlers.NewSyncWorker().Run(ctx)
+	// Публикация отложенных постов
+	go handlers.NewPostScheduler().Run(ctx)
+	// Уборка загруженных картинок, на которые больше никто не ссылается
+	go handlers.NewUploadGCWorker().Run(ctx)
+
+	r := newRouter()
+
+	// Запускаем сервер
+	listen := os.Getenv("LISTEN")
+	if listen == "" {
+		listen = ":8080"
+	}
+	if logger.Enabled() {
+		logger.Infof("Server starting on %s", listen)
+	} else {
+		log.Println("Server listening on", listen)
+	}
+	log.Fatal(http.ListenAndServe(listen, r))
+}
 
-	// Создаём основной роутер
+// newRouter — все маршруты сайта; его же обходит статическая выгрузка
+func newRouter() *mux.Router {
 	r := mux.NewRouter()
 
-	// Статика для загруженных картинок (если используете uploads)
-	r.PathPrefix("/uploads/").Handler(
-		http.StripPrefix("/uploads/", http.FileServer(http.Dir("static/uploads"))),
+	// Загруженные картинки: с диска или из S3 (если у бакета нет своего публичного адреса)
+	r.PathPrefix(uploads.URLPrefix).Handler(
+		http.StripPrefix(uploads.URLPrefix, storage.Handler(uploads.Default.Backend)),
 	)
 
 	// Публичные маршруты
 	r.HandleFunc("/", handlers.RootHandler).Methods("GET")
 	r.HandleFunc("/projects", handlers.ProjectsPage).Methods("GET")
+	r.HandleFunc("/projects/{repo}", handlers.ProjectPage).Methods("GET")
 	r.HandleFunc("/login", handlers.LoginHandler).Methods("GET", "POST")
 	r.HandleFunc("/register", handlers.RegisterHandler).Methods("GET", "POST")
 	r.HandleFunc("/logout", handlers.LogoutHandler).Methods("GET", "POST")
@@ -50,10 +111,34 @@ func main() {
 	admin.HandleFunc("/delete_post", handlers.DeletePost).Methods("POST")
 	admin.HandleFunc("/update_post", handlers.UpdatePost).Methods("POST")
 	admin.HandleFunc("/edit_post", handlers.EditPost).Methods("GET")
+	admin.HandleFunc("/posts/revisions", handlers.PostRevisionsPage).Methods("GET")
+	admin.HandleFunc("/posts/revisions/restore", handlers.RestorePostRevision).Methods("POST")
 
 	// Парсинг и сохранение проектов
 	admin.HandleFunc("/projects/refresh", handlers.RefreshProjects).Methods("POST")
 	admin.HandleFunc("/projects/save", handlers.SaveProjects).Methods("POST")
+	admin.HandleFunc("/projects/order", handlers.ReorderProjects).Methods("POST")
+	admin.HandleFunc("/projects/manual/save", handlers.SaveManualProject).Methods("POST")
+	admin.HandleFunc("/projects/manual/delete", handlers.DeleteManualProject).Methods("POST")
+	admin.HandleFunc("/projects/sources/sync", handlers.SyncSourceNow).Methods("POST")
+	admin.HandleFunc("/projects/sources/schedule", handlers.ScheduleSource).Methods("POST")
+	admin.HandleFunc("/projects/sources/delete", handlers.DeleteSource).Methods("POST")
+
+	// Медиатека загруженных картинок
+	admin.HandleFunc("/media/upload", handlers.MediaUpload).Methods("POST")
+	admin.HandleFunc("/media/use", handlers.MediaUse).Methods("POST")
+	admin.HandleFunc("/media/delete", handlers.MediaDelete).Methods("POST")
+
+	// Статическая копия своего профиля (zip); страницы берутся из этого же роутера
+	admin.HandleFunc("/export", handlers.ExportProfile(r)).Methods("POST")
+
+	// Пул токенов GitHub — общий, поэтому только для роли admin
+	tokens := admin.PathPrefix("/tokens").Subrouter()
+	tokens.Use(handlers.RequireAdmin)
+	tokens.HandleFunc("", handlers.TokensPage).Methods("GET")
+	tokens.HandleFunc("/add", handlers.TokenAdd).Methods("POST")
+	tokens.HandleFunc("/toggle", handlers.TokenToggle).Methods("POST")
+	tokens.HandleFunc("/delete", handlers.TokenDelete).Methods("POST")
 
 	// Личный кабинет для любого залогиненного пользователя
 	cabinet := r.PathPrefix("").Subrouter()
@@ -63,17 +148,15 @@ func main() {
 
 	// Публичные персональные страницы по слагу — регистрируем в самом конце,
 	// чтобы не перехватить системные пути
+	r.HandleFunc("/{slug}/projects", handlers.ProjectsPage).Methods("GET")
+	r.HandleFunc("/{slug}/projects/{repo}", handlers.ProjectPage).Methods("GET")
+	r.HandleFunc("/{slug}/posts/{post}", handlers.PostPage).Methods("GET")
+	r.HandleFunc("/{slug}/tags/{tag}", handlers.TagPage).Methods("GET")
+	r.HandleFunc("/{slug}/tags/{tag}/feed.xml", handlers.TagFeed).Methods("GET")
+	r.HandleFunc("/{slug}/feed.xml", handlers.ProfileRSS).Methods("GET")
+	r.HandleFunc("/{slug}/atom.xml", handlers.ProfileAtom).Methods("GET")
+	r.HandleFunc("/{slug}/feed.json", handlers.ProfileJSONFeed).Methods("GET")
+	r.HandleFunc("/{slug}/search", handlers.ProfileSearch).Methods("GET")
 	r.HandleFunc("/{slug}", handlers.PublicProfile).Methods("GET")
-
-	// Запускаем сервер
-	listen := os.Getenv("LISTEN")
-	if listen == "" {
-		listen = ":8080"
-	}
-	if logger.Enabled() {
-		logger.Infof("Server starting on %s", listen)
-	} else {
-		log.Println("Server listening on", listen)
-	}
-	log.Fatal(http.ListenAndServe(listen, r))
+	return r
 }
@@ -0,0 +1,95 @@
+package markdown
+
+import (
+	"bytes"
+	"html/template"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// moreMarker — "<!--more-->" (пробелы и регистр не важны) отдельной строкой:
+// всё до него — превью поста
+var moreMarker = regexp.MustCompile(`(?i)^<!--\s*more\s*-->$`)
+
+// wordsPerMinute — скорость чтения для оценки времени
+const wordsPerMinute = 180
+
+func parse(src string) *blackfriday.Node {
+	md := blackfriday.New(blackfriday.WithExtensions(blackfriday.CommonExtensions))
+	return md.Parse([]byte(src))
+}
+
+// BeforeMore — HTML части документа до маркера <!--more-->; false, если
+// маркера нет. Маркер ищется только между блоками, внутри абзаца или
+// блока кода он ничего не делит.
+func BeforeMore(src string, p *Policy) (template.HTML, bool) {
+	found := false
+	cut := func(doc *blackfriday.Node) {
+		for n := doc.FirstChild; n != nil; n = n.Next {
+			if n.Type != blackfriday.HTMLBlock || !moreMarker.Match(bytes.TrimSpace(n.Literal)) {
+				continue
+			}
+			found = true
+			for n != nil {
+				next := n.Next
+				n.Unlink()
+				n = next
+			}
+			return
+		}
+	}
+	out := Render(src, p, cut)
+	if !found {
+		return "", false
+	}
+	return out, true
+}
+
+// Excerpt — начало текста документа без разметки длиной до n символов,
+// обрезанное по границе слова; true, если текст не поместился.
+// Код, сырой HTML и подписи картинок в превью не попадают.
+func Excerpt(src string, n int) (string, bool) {
+	text := plainText(parse(src), false)
+	if utf8.RuneCountInString(text) <= n {
+		return text, false
+	}
+	rs := []rune(text)[:n]
+	cut := string(rs)
+	if i := strings.LastIndexAny(cut, " \n"); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:—-") + "…", true
+}
+
+// ReadingMinutes — оценка времени чтения в минутах (не меньше одной)
+func ReadingMinutes(src string) int {
+	words := len(strings.Fields(plainText(parse(src), true)))
+	return max(1, int(math.Ceil(float64(words)/wordsPerMinute)))
+}
+
+// plainText собирает текст документа, пробелы схлопываются
+func plainText(doc *blackfriday.Node, withCode bool) string {
+	var b strings.Builder
+	doc.Walk(func(n *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		switch n.Type {
+		case blackfriday.Text, blackfriday.Code:
+			b.Write(n.Literal)
+		case blackfriday.CodeBlock:
+			if withCode {
+				b.WriteByte(' ')
+				b.Write(n.Literal)
+			}
+		case blackfriday.Image:
+			return blackfriday.SkipChildren
+		case blackfriday.Softbreak, blackfriday.Hardbreak, blackfriday.Paragraph,
+			blackfriday.Heading, blackfriday.Item, blackfriday.TableCell, blackfriday.BlockQuote:
+			b.WriteByte(' ')
+		}
+		return blackfriday.GoToNext
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
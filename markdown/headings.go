@@ -0,0 +1,91 @@
+package markdown
+
+import (
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// Heading — заголовок документа для оглавления
+type Heading struct {
+	Level int
+	ID    string
+	Text  string
+}
+
+// anchorClass — класс ссылки-якоря рядом с заголовком (есть в Policy.Classes)
+const anchorClass = "heading-anchor"
+
+// anchorHeadings выдаёт заголовкам id (явный {#id} сохраняется, иначе —
+// из текста, с суффиксом при повторе), добавляет ссылку-якорь и
+// возвращает заголовки по порядку
+func anchorHeadings(doc *blackfriday.Node) []Heading {
+	var out []Heading
+	used := map[string]int{}
+	doc.Walk(func(n *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if !entering || n.Type != blackfriday.Heading || n.IsTitleblock {
+			return blackfriday.GoToNext
+		}
+		text := nodeText(n)
+		id := n.HeadingID
+		if id == "" {
+			id = headingID(text)
+		}
+		if k := used[id]; k > 0 {
+			used[id] = k + 1
+			id += "-" + strconv.Itoa(k)
+		} else {
+			used[id] = 1
+		}
+		n.HeadingID = id
+
+		anchor := blackfriday.NewNode(blackfriday.HTMLSpan)
+		anchor.Literal = []byte(` <a class="` + anchorClass + `" href="#` + html.EscapeString(id) + `">#</a>`)
+		n.AppendChild(anchor)
+
+		out = append(out, Heading{Level: n.Level, ID: id, Text: text})
+		return blackfriday.SkipChildren
+	})
+	return out
+}
+
+// nodeText — текст узла без разметки
+func nodeText(n *blackfriday.Node) string {
+	var b strings.Builder
+	n.Walk(func(c *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if entering && (c.Type == blackfriday.Text || c.Type == blackfriday.Code) {
+			b.Write(c.Literal)
+		}
+		return blackfriday.GoToNext
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// headingID — id из текста заголовка: буквы и цифры в нижнем регистре
+// (кириллица остаётся как есть), остальное — дефисы
+func headingID(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	id := b.String()
+	if rs := []rune(id); len(rs) > 64 {
+		id = strings.TrimRight(string(rs[:64]), "-")
+	}
+	if id == "" {
+		return "section"
+	}
+	return id
+}
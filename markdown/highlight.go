@@ -0,0 +1,39 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// Подсветка кода на сервере: цвета — inline-стилями, чтобы блоки
+// выглядели одинаково на странице поста, в README и в RSS-ридерах
+var (
+	hlFormatter = chromahtml.New(chromahtml.WithClasses(false), chromahtml.TabWidth(4))
+	hlStyle     = styles.Get("github")
+)
+
+// highlight раскрашивает код по языку из info-строки блока (```go);
+// false — язык не указан или неизвестен, блок рендерится как есть
+func highlight(code, info string) (string, bool) {
+	lang, _, _ := strings.Cut(strings.TrimSpace(info), " ")
+	if lang == "" {
+		return "", false
+	}
+	lexer := lexers.Get(strings.ToLower(lang))
+	if lexer == nil {
+		return "", false
+	}
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if err := hlFormatter.Format(&b, hlStyle, it); err != nil {
+		return "", false
+	}
+	return b.String(), true
+}
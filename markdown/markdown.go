@@ -0,0 +1,71 @@
+// Package markdown — рендеринг Markdown в безопасный HTML: blackfriday
+// с расширениями сайта и санитайзер по allow-list (Policy) поверх
+// результата, включая встроенный в Markdown сырой HTML.
+package markdown
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// Transform меняет дерево документа перед рендерингом
+// (например, переписывает относительные адреса README)
+type Transform func(doc *blackfriday.Node)
+
+// Document — отрендеренный документ и его заголовки (для оглавления)
+type Document struct {
+	HTML     template.HTML
+	Headings []Heading
+}
+
+// Render превращает Markdown в HTML и чистит его политикой p
+// (nil — Default). Трансформации применяются по порядку.
+func Render(src string, p *Policy, transforms ...Transform) template.HTML {
+	return RenderDocument(src, p, transforms...).HTML
+}
+
+// RenderDocument — Render с оглавлением. Заголовки получают id и
+// ссылки-якоря, блоки кода с указанным языком подсвечиваются. Подсветка
+// подставляется уже после санитайзера вместо меток со случайным nonce:
+// её разметку строит chroma из экранированного текста, а автор поста
+// метку подделать не может.
+func RenderDocument(src string, p *Policy, transforms ...Transform) Document {
+	if p == nil {
+		p = Default()
+	}
+	doc := parse(src)
+	for _, t := range transforms {
+		t(doc)
+	}
+	headings := anchorHeadings(doc)
+
+	nonce := make([]byte, 8)
+	rand.Read(nonce)
+	var blocks []string // пары «метка, подсвеченный код» для strings.Replacer
+	r := blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{Flags: blackfriday.CommonHTMLFlags})
+	var buf bytes.Buffer
+	r.RenderHeader(&buf, doc)
+	doc.Walk(func(n *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if n.Type == blackfriday.CodeBlock {
+			if out, ok := highlight(string(n.Literal), string(n.Info)); ok {
+				mark := fmt.Sprintf("hl-%x-%d-", nonce, len(blocks)/2)
+				blocks = append(blocks, mark, out)
+				buf.WriteString(mark + "\n")
+				return blackfriday.GoToNext
+			}
+		}
+		return r.RenderNode(&buf, n, entering)
+	})
+	r.RenderFooter(&buf, doc)
+
+	out := p.Sanitize(buf.String())
+	if len(blocks) > 0 {
+		out = strings.NewReplacer(blocks...).Replace(out)
+	}
+	return Document{HTML: template.HTML(out), Headings: headings}
+}
@@ -0,0 +1,162 @@
+package markdown
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Policy — allow-list санитайзера: какие теги и атрибуты остаются в HTML
+// после Markdown, какие схемы допустимы в адресах и как помечать внешние
+// ссылки. Всё, чего нет в списке, вырезается.
+type Policy struct {
+	// Elements — разрешённые теги и их атрибуты
+	Elements map[string][]string
+	// Schemes — схемы для href/src; относительные адреса разрешены всегда
+	Schemes []string
+	// LocalHosts — хосты самого сайта; ссылки на другие хосты внешние
+	LocalHosts []string
+	// ExternalRel — rel для внешних ссылок (авторский rel заменяется)
+	ExternalRel string
+	// Classes — допустимые значения class (кроме language-* у кода)
+	Classes []string
+	// EmbedHosts — откуда можно встраивать <iframe> (только https),
+	// без них iframe не пропускается, даже если есть в Elements
+	EmbedHosts []string
+}
+
+// Имена политик для MARKDOWN_POLICY
+const (
+	PolicyStrict  = "strict"
+	PolicyDefault = "default"
+	PolicyRelaxed = "relaxed"
+)
+
+// textElements — разметка текста, которую даёт сам Markdown
+var textElements = map[string][]string{
+	"p": nil, "br": nil, "hr": nil,
+	"h1": {"id"}, "h2": {"id"}, "h3": {"id"}, "h4": {"id"}, "h5": {"id"}, "h6": {"id"},
+	"blockquote": nil, "pre": nil, "code": {"class"},
+	"em": nil, "strong": nil, "del": nil, "s": nil, "b": nil, "i": nil,
+	"sub": nil, "sup": nil, "kbd": nil, "mark": nil, "small": nil, "abbr": {"title"},
+	"ul": nil, "ol": {"start"}, "li": nil, "dl": nil, "dt": nil, "dd": nil,
+	"a": {"href", "title", "class"},
+}
+
+// Strict — только текст, списки, цитаты, код и ссылки; без картинок,
+// таблиц и блочной вёрстки
+func Strict() *Policy {
+	return &Policy{
+		Elements:    copyElements(textElements),
+		Schemes:     []string{"http", "https", "mailto"},
+		ExternalRel: "nofollow noopener",
+		Classes:     []string{anchorClass},
+	}
+}
+
+// Default — Strict плюс картинки, таблицы и то, чем обычно оформляют
+// README: выравнивание блоков, <details>, подписи к рисункам
+func Default() *Policy {
+	p := Strict()
+	for tag, attrs := range map[string][]string{
+		"img":   {"src", "alt", "title", "width", "height", "align"},
+		"table": nil, "thead": nil, "tbody": nil, "tfoot": nil, "tr": nil,
+		"th": {"align", "colspan", "rowspan"}, "td": {"align", "colspan", "rowspan"},
+		"div": {"align"}, "span": nil, "details": {"open"}, "summary": nil,
+		"figure": nil, "figcaption": nil,
+	} {
+		p.Elements[tag] = attrs
+	}
+	p.Elements["p"] = []string{"align"}
+	return p
+}
+
+// Relaxed — Default плюс встраивание видео с известных хостингов
+func Relaxed() *Policy {
+	p := Default()
+	p.Elements["iframe"] = []string{"src", "width", "height", "title", "allowfullscreen"}
+	p.EmbedHosts = []string{
+		"www.youtube.com", "www.youtube-nocookie.com", "player.vimeo.com",
+	}
+	return p
+}
+
+// Named — политика по имени; пустое имя — Default
+func Named(name string) (*Policy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case PolicyStrict:
+		return Strict(), nil
+	case "", PolicyDefault:
+		return Default(), nil
+	case PolicyRelaxed:
+		return Relaxed(), nil
+	}
+	return nil, fmt.Errorf("markdown: unknown policy %q (want strict, default or relaxed)", name)
+}
+
+// FromEnv — политика из MARKDOWN_POLICY; хост из SITE_URL считается своим,
+// ссылки на него не помечаются как внешние
+func FromEnv() (*Policy, error) {
+	p, err := Named(os.Getenv("MARKDOWN_POLICY"))
+	if err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(os.Getenv("SITE_URL")); err == nil && u.Host != "" {
+		p.LocalHosts = append(p.LocalHosts, u.Host)
+	}
+	return p, nil
+}
+
+func copyElements(m map[string][]string) map[string][]string {
+	out := make(map[string][]string, len(m))
+	for k, v := range m {
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+}
+
+func (p *Policy) allowsAttr(tag, attr string) bool {
+	for _, a := range p.Elements[tag] {
+		if a == attr {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) allowsScheme(scheme string) bool {
+	for _, s := range p.Schemes {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) allowsClass(class string) bool {
+	for _, c := range p.Classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) isLocalHost(host string) bool {
+	for _, h := range p.LocalHosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) isEmbedHost(host string) bool {
+	for _, h := range p.EmbedHosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// dropContent — теги, которые при запрете вырезаются вместе с содержимым:
+// исполняемое, стили и всё, чей текст не предназначен для показа
+var dropContent = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"textarea": true, "select": true, "title": true, "head": true,
+	"object": true, "embed": true, "applet": true, "svg": true, "math": true,
+	"iframe": true, "frame": true, "frameset": true, "noembed": true,
+	"noframes": true, "xmp": true, "plaintext": true,
+}
+
+// voidElements — теги без закрывающей пары
+var voidElements = map[string]bool{
+	"br": true, "hr": true, "img": true, "wbr": true, "input": true,
+}
+
+var (
+	idValue    = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}_-]{0,79}$`)
+	langClass  = regexp.MustCompile(`^language-[\w+#.-]{1,40}$`)
+	sizeValue  = regexp.MustCompile(`^\d{1,4}%?$`)
+	alignValue = map[string]bool{"left": true, "right": true, "center": true, "justify": true}
+)
+
+// Sanitize пропускает HTML через allow-list политики. Запрещённые теги
+// выбрасываются с сохранением текста (кроме dropContent), атрибуты и адреса
+// проверяются, незакрытые теги закрываются, комментарии удаляются.
+// Результат — корректно вложенный HTML, безопасный для вставки в страницу.
+func (p *Policy) Sanitize(src string) string {
+	z := html.NewTokenizer(strings.NewReader(src))
+	var out strings.Builder
+	var open []string // открытые разрешённые теги
+	skip, skipDepth := "", 0
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				out.WriteString("</" + open[i] + ">")
+			}
+			return out.String()
+
+		case html.TextToken:
+			if skip == "" {
+				out.WriteString(html.EscapeString(string(z.Text())))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if skip != "" {
+				if tok.Data == skip && tt == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if _, ok := p.Elements[tok.Data]; !ok {
+				if dropContent[tok.Data] && tt == html.StartTagToken {
+					skip, skipDepth = tok.Data, 1
+				}
+				continue
+			}
+			attrs, ok := p.attrs(tok)
+			if !ok {
+				if dropContent[tok.Data] && tt == html.StartTagToken {
+					skip, skipDepth = tok.Data, 1
+				}
+				continue
+			}
+			out.WriteString("<" + tok.Data + attrs + ">")
+			switch {
+			case voidElements[tok.Data]:
+			case tt == html.SelfClosingTagToken:
+				out.WriteString("</" + tok.Data + ">")
+			default:
+				open = append(open, tok.Data)
+			}
+
+		case html.EndTagToken:
+			tok := z.Token()
+			if skip != "" {
+				if tok.Data == skip {
+					if skipDepth--; skipDepth == 0 {
+						skip = ""
+					}
+				}
+				continue
+			}
+			// Закрываем только открытое нами; лишние закрывающие теги теряются
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tok.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					out.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+		// комментарии и doctype пропускаем
+	}
+}
+
+// attrs — разрешённые атрибуты тега в виде ` key="value"...`;
+// false — тег без обязательного адреса или с недопустимым источником
+func (p *Policy) attrs(tok html.Token) (string, bool) {
+	var b strings.Builder
+	seen := map[string]bool{}
+	hasURL, external := false, false
+	for _, a := range tok.Attr {
+		if a.Namespace != "" || seen[a.Key] || !p.allowsAttr(tok.Data, a.Key) {
+			continue
+		}
+		val := a.Val
+		switch a.Key {
+		case "href", "src":
+			host, ok := p.checkURL(val, a.Key == "src")
+			if !ok {
+				continue
+			}
+			if tok.Data == "iframe" && (!strings.HasPrefix(strings.ToLower(strings.TrimSpace(val)), "https://") || !p.isEmbedHost(host)) {
+				return "", false
+			}
+			hasURL = true
+			external = host != "" && !p.isLocalHost(host)
+		case "class":
+			var keep []string
+			for _, c := range strings.Fields(val) {
+				if langClass.MatchString(c) || p.allowsClass(c) {
+					keep = append(keep, c)
+				}
+			}
+			if len(keep) == 0 {
+				continue
+			}
+			val = strings.Join(keep, " ")
+		case "id":
+			if !idValue.MatchString(val) {
+				continue
+			}
+		case "width", "height", "colspan", "rowspan", "start":
+			if !sizeValue.MatchString(strings.TrimSpace(val)) {
+				continue
+			}
+		case "align":
+			val = strings.ToLower(strings.TrimSpace(val))
+			if !alignValue[val] {
+				continue
+			}
+		case "open", "allowfullscreen":
+			val = ""
+		}
+		seen[a.Key] = true
+		b.WriteString(" " + a.Key + `="` + html.EscapeString(val) + `"`)
+	}
+	switch tok.Data {
+	case "img", "iframe":
+		if !hasURL {
+			return "", false
+		}
+	case "a":
+		if external && p.ExternalRel != "" {
+			b.WriteString(` rel="` + html.EscapeString(p.ExternalRel) + `"`)
+		}
+	}
+	return b.String(), true
+}
+
+// checkURL проверяет адрес из href/src: относительный или со схемой из
+// политики (для src — только http/https). Возвращает хост ("" у
+// относительных и mailto:).
+func (p *Policy) checkURL(raw string, isSrc bool) (string, bool) {
+	// Браузер выбрасывает из адреса переводы строк и табы ("java\tscript:")
+	// и считает "\" за "/"
+	v := strings.TrimSpace(raw)
+	v = strings.NewReplacer("\t", "", "\n", "", "\r", "", `\`, "/").Replace(v)
+	if v == "" {
+		return "", false
+	}
+	u, err := url.Parse(v)
+	if err != nil {
+		return "", false
+	}
+	if u.Scheme != "" {
+		s := strings.ToLower(u.Scheme)
+		if !p.allowsScheme(s) || (isSrc && s != "http" && s != "https") {
+			return "", false
+		}
+	}
+	return u.Host, true
+}
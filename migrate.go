@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"Site/db"
+)
+
+// runMigrate — подкоманда `migrate up|down [N]|status`
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: migrate up | down [N] | status")
+		return 2
+	}
+	db.Connect()
+	defer db.ClosePool()
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		n, err := db.MigrateUp(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate up: %v\n", err)
+			return 1
+		}
+		fmt.Printf("applied %d migration(s)\n", n)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			v, err := strconv.Atoi(args[1])
+			if err != nil || v < 1 {
+				fmt.Fprintf(os.Stderr, "migrate down: bad step count %q\n", args[1])
+				return 2
+			}
+			steps = v
+		}
+		n, err := db.MigrateDown(ctx, steps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate down: %v\n", err)
+			return 1
+		}
+		fmt.Printf("rolled back %d migration(s)\n", n)
+	case "status":
+		list, err := db.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate status: %v\n", err)
+			return 1
+		}
+		for _, m := range list {
+			state := "pending"
+			if m.Applied {
+				state = "applied " + m.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if m.Dirty {
+				state += " (checksum mismatch)"
+			}
+			fmt.Printf("%04d_%-30s %s\n", m.Version, m.Name, state)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "migrate: unknown command %q\n", args[0])
+		return 2
+	}
+	return 0
+}
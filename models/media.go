@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"Site/uploads"
+)
+
+// Media — файл в медиатеке пользователя
+type Media struct {
+	ID        int
+	UserID    int
+	URL       string
+	Size      int64
+	Width     int
+	Height    int
+	CreatedAt time.Time
+
+	// Где картинка используется у владельца
+	Projects []string
+	Posts    []string
+	HomeBg   bool
+}
+
+// InUse — на картинку ссылается проект, пост или фон профиля
+func (m Media) InUse() bool {
+	return m.HomeBg || len(m.Projects) > 0 || len(m.Posts) > 0
+}
+
+// PreviewURL — небольшая копия для сетки медиатеки
+func (m Media) PreviewURL() string { return uploads.ThumbURL(m.URL, 320) }
+
+// SizeText — размер файла для людей: 512 Б, 34.5 КБ, 2.1 МБ
+func (m Media) SizeText() string {
+	switch {
+	case m.Size <= 0:
+		return "—"
+	case m.Size < 1<<10:
+		return fmt.Sprintf("%d Б", m.Size)
+	case m.Size < 1<<20:
+		return fmt.Sprintf("%.1f КБ", float64(m.Size)/(1<<10))
+	}
+	return fmt.Sprintf("%.1f МБ", float64(m.Size)/(1<<20))
+}
+
+// Dimensions — «ширина×высота» или «—», если размеры неизвестны
+func (m Media) Dimensions() string {
+	if m.Width == 0 || m.Height == 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%d×%d", m.Width, m.Height)
+}
+
+// Markdown — вставка картинки в текст поста
+func (m Media) Markdown() string { return "![](" + m.URL + ")" }
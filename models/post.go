@@ -1,7 +1,74 @@
 package models
 
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Состояния поста (post.status)
+const (
+	PostDraft     = "draft"
+	PostPublished = "published"
+	// PostScheduled — станет published в PublishedAt (см. handlers.PostScheduler)
+	PostScheduled = "scheduled"
+	PostArchived  = "archived"
+)
+
 type Post struct {
 	ID    int
 	Label string
 	Text  string
+	// Summary — краткое описание для карточек; пустое — превью из текста
+	Summary string
+	Status  string
+	// Slug — адрес поста среди постов автора: /{slug автора}/posts/{Slug}
+	Slug      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// PublishedAt — когда пост опубликован (или будет, если Status == PostScheduled)
+	PublishedAt *time.Time
+	// Tags — теги по алфавиту; заполняются отдельным запросом (handlers.attachPostTags)
+	Tags []Tag
+}
+
+// Path — постоянный адрес поста у автора со слагом userSlug
+func (p Post) Path(userSlug string) string {
+	return "/" + userSlug + "/posts/" + url.PathEscape(p.Slug)
+}
+
+// TagsText — теги через запятую, для формы редактирования
+func (p Post) TagsText() string {
+	names := make([]string, len(p.Tags))
+	for i, t := range p.Tags {
+		names[i] = t.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// IsPublished — пост виден на публичных страницах
+func (p Post) IsPublished() bool { return p.Status == PostPublished }
+
+// StatusLabel — состояние для админки
+func (p Post) StatusLabel() string {
+	switch p.Status {
+	case PostDraft:
+		return "черновик"
+	case PostPublished:
+		return "опубликован"
+	case PostScheduled:
+		return "отложен"
+	case PostArchived:
+		return "в архиве"
+	}
+	return p.Status
+}
+
+// PublishedAtISO — PublishedAt в RFC 3339 (UTC) для формы: в местное
+// время браузера его переводит скрипт админки
+func (p Post) PublishedAtISO() string {
+	if p.PublishedAt == nil {
+		return ""
+	}
+	return p.PublishedAt.UTC().Format(time.RFC3339)
 }
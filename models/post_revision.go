@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PostRevision — сохранённая версия поста
+type PostRevision struct {
+	ID       int
+	PostID   int
+	AuthorID *int
+	// Author — логин автора правки; пусто, если пользователь удалён
+	Author       string
+	Label        string
+	Text         string
+	RestoredFrom *int
+	CreatedAt    time.Time
+}
@@ -1,9 +1,27 @@
 package models
 
-import "time"
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"Site/uploads"
+)
+
+// Происхождение проекта (projects.source)
+const (
+	// ProjectImported — репозиторий с хостинга кода, обновляется синхронизацией
+	ProjectImported = "import"
+	// ProjectManual — заведён вручную, синхронизация его не трогает
+	ProjectManual = "manual"
+)
 
 type Project struct {
 	ID          int
+	UserID      int
+	Provider    string
 	RepoName    string
 	Title       string
 	Description string
@@ -12,4 +30,127 @@ type Project struct {
 	CustomURL   string
 	Enabled     bool
 	UpdatedAt   time.Time
+	// Source — ProjectImported или ProjectManual
+	Source string
+	// Links — дополнительные ссылки (демо, магазин приложений, кейс)
+	Links []ProjectLink
+	// Position — ручной порядок (меньше — выше), Pinned — всегда сверху
+	Position int
+	Pinned   bool
+	// Group — раздел на публичной странице; пусто — без раздела
+	Group string
+
+	// Метаданные репозитория, обновляются синхронизацией
+	Stars     int
+	Forks     int
+	Language  string
+	Languages map[string]float64
+	Topics    []string
+	Homepage  string
+	License   string
+	PushedAt  *time.Time
+	Archived  bool
+}
+
+// LanguageShare — доля языка в репозитории
+type LanguageShare struct {
+	Name    string
+	Percent float64
+}
+
+// LanguageBreakdown — языки по убыванию доли
+func (p Project) LanguageBreakdown() []LanguageShare {
+	list := make([]LanguageShare, 0, len(p.Languages))
+	for name, pct := range p.Languages {
+		list = append(list, LanguageShare{Name: name, Percent: pct})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Percent != list[j].Percent {
+			return list[i].Percent > list[j].Percent
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// ProjectLink — подписанная ссылка проекта
+type ProjectLink struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// LinksText — ссылки построчно "Название | адрес", для формы редактирования
+func (p Project) LinksText() string {
+	lines := make([]string, 0, len(p.Links))
+	for _, l := range p.Links {
+		lines = append(lines, l.Title+" | "+l.URL)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TagsText — теги (у импортированных — темы репозитория) через запятую
+func (p Project) TagsText() string { return strings.Join(p.Topics, ", ") }
+
+// CardImageURL — картинка для карточки: уменьшенная копия загрузки
+// или исходный адрес (аватар, внешняя ссылка)
+func (p Project) CardImageURL() string {
+	return uploads.ThumbURL(p.ImageURL, 640)
+}
+
+// ImageSrcset — srcset уменьшенных копий загруженной картинки, "" если их нет
+func (p Project) ImageSrcset() string {
+	return uploads.Srcset(p.ImageURL)
+}
+
+// PagePath — адрес страницы проекта у владельца со слагом slug.
+// Одноимённые проекты различаются ?provider= (у ручных — "manual").
+func (p Project) PagePath(slug string) string {
+	page := "/" + slug + "/projects/" + url.PathEscape(p.RepoName)
+	switch {
+	case p.IsManual():
+		page += "?provider=manual"
+	case p.Provider != "github.com":
+		page += "?provider=" + url.QueryEscape(p.Provider)
+	}
+	return page
+}
+
+// IsManual — проект заведён вручную
+func (p Project) IsManual() bool { return p.Source == ProjectManual }
+
+// ProviderLabel — подпись кнопки-ссылки на репозиторий
+func (p Project) ProviderLabel() string {
+	switch p.Provider {
+	case "":
+		// ручной проект: ссылка на репозиторий где угодно
+		return "Репозиторий"
+	case "github.com":
+		return "GitHub"
+	case "gitlab.com":
+		return "GitLab"
+	case "codeberg.org":
+		return "Codeberg"
+	case "bitbucket.org":
+		return "Bitbucket"
+	}
+	return p.Provider
+}
+
+// ProjectReadme — закэшированный README проекта
+type ProjectReadme struct {
+	Path    string
+	Content string
+	// RawRoot и WebRoot — корни репозитория для относительных картинок и ссылок
+	RawRoot   string
+	WebRoot   string
+	UpdatedAt time.Time
+}
+
+// IsMarkdown — README в разметке Markdown (а не README.txt)
+func (r ProjectReadme) IsMarkdown() bool {
+	switch strings.ToLower(path.Ext(r.Path)) {
+	case ".md", ".markdown", ".mdown", ".mkd":
+		return true
+	}
+	return false
 }
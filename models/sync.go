@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ProjectSource — сохранённый источник проектов (GitHub user или owner/repo)
+type ProjectSource struct {
+	ID       int
+	UserID   int
+	Provider string
+	Source   string
+	// IntervalMinutes: nil — интервал по умолчанию, 0 — только вручную
+	IntervalMinutes *int
+	LastSyncAt      *time.Time
+	LastStatus      string
+	LastError       string
+	// Фильтры списка репозиториев
+	IncludeForks    bool
+	IncludeArchived bool
+	IncludePrivate  bool
+}
+
+// SyncRun — одна запись истории синхронизации
+type SyncRun struct {
+	ID         int
+	SourceID   int
+	Provider   string
+	Source     string
+	Trigger    string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	Status     string
+	Error      string
+	ReposCount int
+}
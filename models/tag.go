@@ -0,0 +1,18 @@
+package models
+
+import "net/url"
+
+// Tag — тег постов автора
+type Tag struct {
+	ID     int
+	UserID int
+	Name   string
+	Slug   string
+	// Count — число опубликованных постов с тегом (для облака тегов)
+	Count int
+}
+
+// Path — страница тега у автора со слагом userSlug
+func (t Tag) Path(userSlug string) string {
+	return "/" + userSlug + "/tags/" + url.PathEscape(t.Slug)
+}
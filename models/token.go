@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// GitHubToken — токен из пула и его последнее известное состояние лимитов.
+// Исторически таблица github_tokens, теперь в ней токены всех провайдеров.
+type GitHubToken struct {
+	ID            int
+	Provider      string
+	Token         string
+	Enabled       bool
+	FailCount     int
+	LastUsedAt    *time.Time
+	RateLimit     *int
+	RateRemaining *int
+	RateResetAt   *time.Time
+	CooldownUntil *time.Time
+	LastStatus    *int
+	LastError     string
+	CheckedAt     *time.Time
+}
+
+// Masked — токен для показа в админке (только последние 4 символа)
+func (t GitHubToken) Masked() string {
+	if len(t.Token) <= 4 {
+		return "****"
+	}
+	return "…" + t.Token[len(t.Token)-4:]
+}
+
+// Paused — токен на паузе после лимита
+func (t GitHubToken) Paused(now time.Time) bool {
+	return t.CooldownUntil != nil && t.CooldownUntil.After(now)
+}
+
+// Exhausted — квота исчерпана и ещё не сбросилась
+func (t GitHubToken) Exhausted(now time.Time) bool {
+	return t.RateRemaining != nil && *t.RateRemaining == 0 &&
+		t.RateResetAt != nil && t.RateResetAt.After(now)
+}
@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"Site/tokenpool"
+)
+
+// bbRepo описывает JSON-ответ Bitbucket Cloud API 2.0
+type bbRepo struct {
+	Slug        string     `json:"slug"`
+	FullName    string     `json:"full_name"`
+	Description string     `json:"description"`
+	IsPrivate   bool       `json:"is_private"`
+	Language    string     `json:"language"`
+	Website     string     `json:"website"`
+	UpdatedOn   *time.Time `json:"updated_on"`
+	MainBranch  struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Parent    json.RawMessage `json:"parent"`
+	Workspace struct {
+		Slug string `json:"slug"`
+	} `json:"workspace"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+		Avatar struct {
+			Href string `json:"href"`
+		} `json:"avatar"`
+	} `json:"links"`
+}
+
+func (r bbRepo) repo() Repo {
+	return Repo{
+		Name:        r.Slug,
+		FullName:    r.FullName,
+		Description: r.Description,
+		HTMLURL:     r.Links.HTML.Href,
+		AvatarURL:   r.Links.Avatar.Href,
+		OwnerLogin:  r.Workspace.Slug,
+		Fork:        len(r.Parent) > 0 && string(r.Parent) != "null",
+		Private:     r.IsPrivate,
+		Language:    r.Language,
+		Homepage:    r.Website,
+		PushedAt:    r.UpdatedOn,
+
+		DefaultBranch: r.MainBranch.Name,
+	}
+}
+
+// bitbucket — Bitbucket Cloud; архивных репозиториев, звёзд и тем там нет
+type bitbucket struct {
+	id, web, api string
+	c            *apiClient
+}
+
+func newBitbucket(id, web string) *bitbucket {
+	return &bitbucket{
+		id:  id,
+		web: web,
+		api: "https://api.bitbucket.org/2.0",
+		c: newAPIClient(id, newHTTPClient(""), "application/json",
+			func(req *http.Request, token string) { req.Header.Set("Authorization", "Bearer "+token) }),
+	}
+}
+
+func (b *bitbucket) ID() string              { return b.id }
+func (b *bitbucket) Kind() string            { return "bitbucket" }
+func (b *bitbucket) BaseURL() string         { return b.web }
+func (b *bitbucket) Tokens() *tokenpool.Pool { return b.c.tokens }
+
+func (b *bitbucket) GetRepo(ctx context.Context, path string) (Repo, error) {
+	ws, slug, ok := strings.Cut(path, "/")
+	if !ok || strings.Contains(slug, "/") {
+		return Repo{}, ErrBadSource
+	}
+	var r bbRepo
+	apiURL := fmt.Sprintf("%s/repositories/%s/%s", b.api, url.PathEscape(ws), url.PathEscape(slug))
+	if _, err := b.c.getJSON(ctx, apiURL, &r); err != nil {
+		return Repo{}, err
+	}
+	return r.repo(), nil
+}
+
+// Readme — /src/{branch}/{file} отдаёт файл как есть, без JSON
+func (b *bitbucket) Readme(ctx context.Context, repo Repo) (*Readme, error) {
+	ws, slug, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return nil, ErrBadSource
+	}
+	if repo.DefaultBranch == "" {
+		return &Readme{}, nil
+	}
+	c := b.c.forRepo(repo)
+	return firstReadme(func(file string) (*Readme, error) {
+		apiURL := fmt.Sprintf("%s/repositories/%s/%s/src/%s/%s", b.api,
+			url.PathEscape(ws), url.PathEscape(slug), url.PathEscape(repo.DefaultBranch), url.PathEscape(file))
+		body, _, err := c.getBody(ctx, apiURL)
+		if err != nil {
+			return nil, err
+		}
+		return &Readme{
+			Path:    file,
+			Content: string(body),
+			RawRoot: repo.HTMLURL + "/raw/" + repo.DefaultBranch + "/",
+			WebRoot: repo.HTMLURL + "/src/" + repo.DefaultBranch + "/",
+		}, nil
+	})
+}
+
+// ListRepos — репозитории рабочего пространства; пагинация через поле next
+func (b *bitbucket) ListRepos(ctx context.Context, workspace string, opts ListOptions) ([]Repo, error) {
+	if strings.Contains(workspace, "/") {
+		return nil, ErrBadSource
+	}
+	apiURL := fmt.Sprintf("%s/repositories/%s?pagelen=100", b.api, url.PathEscape(workspace))
+	var all []Repo
+	for page := 0; apiURL != "" && page < maxPages; page++ {
+		var resp struct {
+			Values []bbRepo `json:"values"`
+			Next   string   `json:"next"`
+		}
+		if _, err := b.c.getJSON(ctx, apiURL, &resp); err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Values {
+			all = append(all, r.repo())
+		}
+		apiURL = resp.Next
+	}
+	return all, nil
+}
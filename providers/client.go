@@ -0,0 +1,308 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"Site/db"
+	"Site/logger"
+	"Site/models"
+	"Site/tokenpool"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maxPages ограничивает пагинацию (100 × 50 = 5000 репозиториев)
+const maxPages = 50
+
+// apiClient — общий HTTP-слой провайдеров: ротация токенов из пула,
+// условные запросы с кэшем ETag и разбор ошибок.
+type apiClient struct {
+	provider string
+	http     *http.Client
+	tokens   *tokenpool.Pool
+	accept   string
+	// auth проставляет токен в запрос (у каждого API свой заголовок)
+	auth func(req *http.Request, token string)
+	// token — закреплённый токен (см. pinned): все запросы идут только с
+	// ним, без ротации и без повтора анонимно
+	token *models.GitHubToken
+	// noStore — ответы не кэшируются (см. forRepo)
+	noStore bool
+}
+
+func newAPIClient(provider string, httpClient *http.Client, accept string, auth func(*http.Request, string)) *apiClient {
+	return &apiClient{
+		provider: provider,
+		http:     httpClient,
+		tokens:   tokenpool.New(provider),
+		accept:   accept,
+		auth:     auth,
+	}
+}
+
+// pinned — копия клиента, которая ходит в API с одним токеном из пула.
+// Нужна для запросов, ответ на которые зависит от токена (/user/...):
+// владелец токена и его репозитории должны определяться одним и тем же токеном.
+func (c *apiClient) pinned(ctx context.Context) (*apiClient, error) {
+	tok, err := c.tokens.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pc := *c
+	pc.token = tok
+	return &pc, nil
+}
+
+// forRepo — клиент для запросов содержимого репозитория. Содержимое
+// приватного репозитория (README) в общий кэш не кладём: ключ кэша — только
+// адрес, и по нему ответ не должен пережить доступ к репозиторию.
+func (c *apiClient) forRepo(repo Repo) *apiClient {
+	if !repo.Private {
+		return c
+	}
+	nc := *c
+	nc.noStore = true
+	return &nc
+}
+
+func (c *apiClient) newRequest(ctx context.Context, apiURL string, hdr http.Header) *http.Request {
+	req, _ := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	for k, vs := range hdr {
+		req.Header[k] = vs
+	}
+	if c.accept != "" {
+		req.Header.Set("Accept", c.accept)
+	}
+	return req
+}
+
+// get выполняет GET с ротацией токенов из пула. Токены на паузе
+// (исчерпанная квота, вторичный лимит) пул не выдаёт; если подходящих
+// токенов не осталось — повторяет запрос без авторизации.
+func (c *apiClient) get(ctx context.Context, apiURL string, hdr http.Header) (*http.Response, error) {
+	if c.token != nil {
+		req := c.newRequest(ctx, apiURL, hdr)
+		c.auth(req, c.token.Token)
+		resp, err := c.http.Do(req)
+		if err != nil {
+			c.tokens.ReportError(ctx, c.token, err)
+			return nil, err
+		}
+		c.tokens.Report(ctx, c.token, resp)
+		return resp, nil
+	}
+	maxTries := 10
+	for tried := 0; tried < maxTries; tried++ {
+		tok, terr := c.tokens.Acquire(ctx)
+		if terr != nil {
+			if !errors.Is(terr, tokenpool.ErrNoToken) {
+				logger.Errorf("providers: %s acquire token error: %v", c.provider, terr)
+			}
+			break
+		}
+		req := c.newRequest(ctx, apiURL, hdr)
+		c.auth(req, tok.Token)
+		resp, err := c.http.Do(req)
+		if err != nil {
+			// network error, try next token
+			c.tokens.ReportError(ctx, tok, err)
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			continue
+		}
+		// Лимиты и 401 — пробуем следующий токен, остальное отдаём как есть
+		if c.tokens.Report(ctx, tok, resp).Retry() {
+			resp.Body.Close()
+			continue
+		}
+		return resp, nil
+	}
+	// fallback without token
+	return c.http.Do(c.newRequest(ctx, apiURL, hdr))
+}
+
+// cacheEntry — сохранённый ответ API для условного запроса
+type cacheEntry struct {
+	ETag         string
+	LastModified string
+	Link         string
+	Body         []byte
+}
+
+// cacheKey — ключ api_http_cache для запроса. Ответ, снятый закреплённым
+// токеном, и ответы /user/... зависят от токена: чужой токен не должен
+// получить их из кэша (в том числе на 304). Поэтому в ключе таких ответов
+// провайдер и id токена, а без закреплённого токена они не кэшируются
+// вовсе ("" — не кэшировать).
+func (c *apiClient) cacheKey(apiURL string) string {
+	if c.noStore {
+		return ""
+	}
+	if c.token != nil {
+		return fmt.Sprintf("%s token:%d %s", c.provider, c.token.ID, apiURL)
+	}
+	if tokenScoped(apiURL) {
+		return ""
+	}
+	return apiURL
+}
+
+// tokenScoped — эндпоинт текущего пользователя (/user, /user/repos):
+// ответ определяется токеном, а не адресом
+func tokenScoped(apiURL string) bool {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return true
+	}
+	return strings.HasSuffix(u.Path, "/user") || strings.Contains(u.Path, "/user/")
+}
+
+func loadCache(ctx context.Context, key string) (*cacheEntry, error) {
+	var e cacheEntry
+	err := db.Pool.QueryRow(ctx,
+		`SELECT etag, last_modified, link, body FROM api_http_cache WHERE url=$1`, key,
+	).Scan(&e.ETag, &e.LastModified, &e.Link, &e.Body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func saveCache(ctx context.Context, key string, e cacheEntry) error {
+	_, err := db.Pool.Exec(ctx, `
+        INSERT INTO api_http_cache(url, etag, last_modified, link, body, fetched_at)
+        VALUES($1,$2,$3,$4,$5,NOW())
+        ON CONFLICT(url) DO UPDATE SET
+          etag = EXCLUDED.etag, last_modified = EXCLUDED.last_modified,
+          link = EXCLUDED.link, body = EXCLUDED.body, fetched_at = NOW()`,
+		key, e.ETag, e.LastModified, e.Link, e.Body)
+	return err
+}
+
+// getJSON выполняет условный GET (см. getBody) и декодирует ответ в v
+func (c *apiClient) getJSON(ctx context.Context, apiURL string, v any) (http.Header, error) {
+	body, h, err := c.getBody(ctx, apiURL)
+	if err != nil {
+		return h, err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return h, fmt.Errorf("JSON decode: %w", err)
+	}
+	return h, nil
+}
+
+// getBody выполняет условный GET и возвращает тело ответа.
+// Если ответ есть в кэше, отправляются If-None-Match/If-Modified-Since;
+// 304 означает «без изменений» (и не расходует квоту) — тогда
+// возвращается сохранённое тело, а Link берётся из кэша для пагинации.
+func (c *apiClient) getBody(ctx context.Context, apiURL string) ([]byte, http.Header, error) {
+	key := c.cacheKey(apiURL)
+	var cached *cacheEntry
+	if key != "" {
+		var err error
+		if cached, err = loadCache(ctx, key); err != nil {
+			logger.Errorf("providers: load cache error (%s): %v", key, err)
+		}
+	}
+	hdr := http.Header{}
+	if cached != nil {
+		if cached.ETag != "" {
+			hdr.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			hdr.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.get(ctx, apiURL, hdr)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	var body []byte
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		logger.Debugf("providers: %s not modified", apiURL)
+		body = cached.Body
+		if resp.Header.Get("Link") == "" && cached.Link != "" {
+			resp.Header.Set("Link", cached.Link)
+		}
+	case resp.StatusCode == http.StatusOK:
+		if body, err = io.ReadAll(resp.Body); err != nil {
+			return nil, resp.Header, fmt.Errorf("read body: %w", err)
+		}
+		e := cacheEntry{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Link:         resp.Header.Get("Link"),
+			Body:         body,
+		}
+		if key != "" && (e.ETag != "" || e.LastModified != "") {
+			if err := saveCache(ctx, key, e); err != nil {
+				logger.Errorf("providers: save cache error (%s): %v", key, err)
+			}
+		}
+	default:
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, resp.Header, &APIError{Provider: c.provider, Status: resp.StatusCode, Body: string(b)}
+	}
+	return body, resp.Header, nil
+}
+
+// getPages проходит по страницам списка, следуя Link: rel="next";
+// convert переводит элементы страницы в Repo.
+func getPages[T any](ctx context.Context, c *apiClient, apiURL string, convert func(T) Repo) ([]Repo, error) {
+	var all []Repo
+	for page := 0; apiURL != "" && page < maxPages; page++ {
+		var items []T
+		h, err := c.getJSON(ctx, apiURL, &items)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			all = append(all, convert(it))
+		}
+		apiURL = linkRelNext(h)
+	}
+	return all, nil
+}
+
+// linkRelNext достаёт URL rel="next" из заголовка Link.
+// Запятые могут встречаться внутри URL, поэтому режем по угловым скобкам.
+func linkRelNext(h http.Header) string {
+	for _, link := range h.Values("Link") {
+		for link != "" {
+			start := strings.IndexByte(link, '<')
+			end := strings.IndexByte(link, '>')
+			if start < 0 || end < start {
+				break
+			}
+			target := link[start+1 : end]
+			params := link[end+1:]
+			if next := strings.IndexByte(params, '<'); next >= 0 {
+				link = params[next:]
+				params = params[:next]
+			} else {
+				link = ""
+			}
+			for _, p := range strings.Split(params, ";") {
+				if strings.TrimSpace(strings.Trim(strings.TrimSpace(p), ",")) == `rel="next"` {
+					return target
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"Site/tokenpool"
+)
+
+// gtRepo — у Gitea/Forgejo формат как у GitHub, но часть полей названа иначе
+type gtRepo struct {
+	ghRepo
+	AvatarURL  string     `json:"avatar_url"`
+	StarsCount int        `json:"stars_count"`
+	Website    string     `json:"website"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+	// Licenses — SPDX-идентификаторы (Gitea 1.22+)
+	Licenses []string `json:"licenses"`
+}
+
+func (r gtRepo) repo() Repo {
+	out := r.ghRepo.repo()
+	if r.AvatarURL != "" {
+		out.AvatarURL = r.AvatarURL
+	}
+	out.Stars = r.StarsCount
+	out.Homepage = r.Website
+	// Времени последнего push Gitea не отдаёт — берём время обновления
+	out.PushedAt = r.UpdatedAt
+	out.License = strings.Join(r.Licenses, ", ")
+	return out
+}
+
+// gitea — Gitea или Forgejo (codeberg.org и self-hosted)
+type gitea struct {
+	id, web, api string
+	c            *apiClient
+}
+
+func newGitea(id, web string) *gitea {
+	return &gitea{
+		id:  id,
+		web: web,
+		api: web + "/api/v1",
+		c: newAPIClient(id, newHTTPClient(""), "application/json",
+			func(req *http.Request, token string) { req.Header.Set("Authorization", "token "+token) }),
+	}
+}
+
+func (g *gitea) ID() string              { return g.id }
+func (g *gitea) Kind() string            { return "gitea" }
+func (g *gitea) BaseURL() string         { return g.web }
+func (g *gitea) Tokens() *tokenpool.Pool { return g.c.tokens }
+
+func (g *gitea) GetRepo(ctx context.Context, path string) (Repo, error) {
+	owner, name, ok := strings.Cut(path, "/")
+	if !ok || strings.Contains(name, "/") {
+		return Repo{}, ErrBadSource
+	}
+	var r gtRepo
+	apiURL := fmt.Sprintf("%s/repos/%s/%s", g.api, url.PathEscape(owner), url.PathEscape(name))
+	if _, err := g.c.getJSON(ctx, apiURL, &r); err != nil {
+		return Repo{}, err
+	}
+	return r.repo(), nil
+}
+
+// Languages — тот же эндпоинт, что у GitHub, тоже в байтах
+func (g *gitea) Languages(ctx context.Context, repo Repo) (map[string]float64, error) {
+	return repoLanguages(ctx, g.c, g.api, repo.FullName)
+}
+
+// Readme — отдельного эндпоинта нет, перебираем имена через contents API
+func (g *gitea) Readme(ctx context.Context, repo Repo) (*Readme, error) {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return nil, ErrBadSource
+	}
+	if repo.DefaultBranch == "" {
+		// Пустой репозиторий
+		return &Readme{}, nil
+	}
+	c := g.c.forRepo(repo)
+	return firstReadme(func(file string) (*Readme, error) {
+		var f fileContent
+		apiURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s", g.api,
+			url.PathEscape(owner), url.PathEscape(name), url.PathEscape(file), url.QueryEscape(repo.DefaultBranch))
+		if _, err := c.getJSON(ctx, apiURL, &f); err != nil {
+			return nil, err
+		}
+		return contentReadme(f)
+	})
+}
+
+func (g *gitea) ListRepos(ctx context.Context, name string, opts ListOptions) ([]Repo, error) {
+	if strings.Contains(name, "/") {
+		return nil, ErrBadSource
+	}
+	// Сначала как организацию, на 404 — как пользователя
+	var org struct {
+		ID int `json:"id"`
+	}
+	_, err := g.c.getJSON(ctx, fmt.Sprintf("%s/orgs/%s", g.api, url.PathEscape(name)), &org)
+	switch {
+	case err == nil:
+		return getPages(ctx, g.c,
+			fmt.Sprintf("%s/orgs/%s/repos?limit=50", g.api, url.PathEscape(name)), gtRepo.repo)
+	case !IsNotFound(err):
+		return nil, err
+	}
+	return getPages(ctx, g.c,
+		fmt.Sprintf("%s/users/%s/repos?limit=50", g.api, url.PathEscape(name)), gtRepo.repo)
+}
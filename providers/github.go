@@ -0,0 +1,221 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"Site/logger"
+	"Site/tokenpool"
+)
+
+// ghRepo описывает JSON-ответ GitHub API
+type ghRepo struct {
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	HTMLURL     string `json:"html_url"`
+	Fork        bool   `json:"fork"`
+	Archived    bool   `json:"archived"`
+	Private     bool   `json:"private"`
+	Owner       struct {
+		Login     string `json:"login"`
+		AvatarURL string `json:"avatar_url"`
+	} `json:"owner"`
+	Stars    int        `json:"stargazers_count"`
+	Forks    int        `json:"forks_count"`
+	Language string     `json:"language"`
+	Topics   []string   `json:"topics"`
+	Homepage string     `json:"homepage"`
+	PushedAt *time.Time `json:"pushed_at"`
+	// DefaultBranch — пусто у пустого репозитория
+	DefaultBranch string `json:"default_branch"`
+	License       *struct {
+		SPDXID string `json:"spdx_id"`
+		Name   string `json:"name"`
+	} `json:"license"`
+}
+
+// licenseName — SPDX-идентификатор, а для нераспознанных ("NOASSERTION") — название
+func (r ghRepo) licenseName() string {
+	if r.License == nil {
+		return ""
+	}
+	if r.License.SPDXID != "" && r.License.SPDXID != "NOASSERTION" {
+		return r.License.SPDXID
+	}
+	return r.License.Name
+}
+
+func (r ghRepo) repo() Repo {
+	return Repo{
+		Name:        r.Name,
+		FullName:    r.FullName,
+		Description: r.Description,
+		HTMLURL:     r.HTMLURL,
+		AvatarURL:   r.Owner.AvatarURL,
+		OwnerLogin:  r.Owner.Login,
+		Fork:        r.Fork,
+		Archived:    r.Archived,
+		Private:     r.Private,
+		Stars:       r.Stars,
+		Forks:       r.Forks,
+		Language:    r.Language,
+		Topics:      r.Topics,
+		Homepage:    r.Homepage,
+		License:     r.licenseName(),
+		PushedAt:    r.PushedAt,
+
+		DefaultBranch: r.DefaultBranch,
+	}
+}
+
+// gitHub — github.com или GitHub Enterprise
+type gitHub struct {
+	id, web, api string
+	c            *apiClient
+}
+
+// newGitHub: для github.com API — api.github.com (GITHUB_API_URL позволяет
+// подменить его фейковым сервером), для Enterprise — {web}/api/v3.
+// GITHUB_PROXY задаёт прокси только для github.com.
+func newGitHub(id, web string) *gitHub {
+	api := web + "/api/v3"
+	proxy := ""
+	if id == "github.com" {
+		api = "https://api.github.com"
+		if u := strings.TrimSpace(os.Getenv("GITHUB_API_URL")); u != "" {
+			api = strings.TrimRight(u, "/")
+		}
+		proxy = os.Getenv("GITHUB_PROXY")
+	}
+	return &gitHub{
+		id:  id,
+		web: web,
+		api: api,
+		c: newAPIClient(id, newHTTPClient(proxy), "application/vnd.github.v3+json",
+			func(req *http.Request, token string) { req.Header.Set("Authorization", "token "+token) }),
+	}
+}
+
+func (g *gitHub) ID() string              { return g.id }
+func (g *gitHub) Kind() string            { return "github" }
+func (g *gitHub) BaseURL() string         { return g.web }
+func (g *gitHub) Tokens() *tokenpool.Pool { return g.c.tokens }
+
+func (g *gitHub) GetRepo(ctx context.Context, path string) (Repo, error) {
+	owner, name, ok := strings.Cut(path, "/")
+	if !ok || strings.Contains(name, "/") {
+		return Repo{}, ErrBadSource
+	}
+	var r ghRepo
+	apiURL := fmt.Sprintf("%s/repos/%s/%s", g.api, url.PathEscape(owner), url.PathEscape(name))
+	if _, err := g.c.getJSON(ctx, apiURL, &r); err != nil {
+		return Repo{}, err
+	}
+	return r.repo(), nil
+}
+
+// Languages — /repos/{owner}/{repo}/languages отдаёт размеры в байтах
+func (g *gitHub) Languages(ctx context.Context, repo Repo) (map[string]float64, error) {
+	return repoLanguages(ctx, g.c, g.api, repo.FullName)
+}
+
+// Readme — /repos/{owner}/{repo}/readme сам находит README в корне
+func (g *gitHub) Readme(ctx context.Context, repo Repo) (*Readme, error) {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return nil, ErrBadSource
+	}
+	var f fileContent
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/readme", g.api, url.PathEscape(owner), url.PathEscape(name))
+	if _, err := g.c.forRepo(repo).getJSON(ctx, apiURL, &f); err != nil {
+		if IsNotFound(err) {
+			return &Readme{}, nil
+		}
+		return nil, err
+	}
+	return contentReadme(f)
+}
+
+// repoLanguages — общий для GitHub и Gitea эндпоинт разбивки по языкам
+func repoLanguages(ctx context.Context, c *apiClient, api, fullName string) (map[string]float64, error) {
+	owner, name, ok := strings.Cut(fullName, "/")
+	if !ok {
+		return nil, ErrBadSource
+	}
+	var sizes map[string]float64
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/languages", api, url.PathEscape(owner), url.PathEscape(name))
+	if _, err := c.getJSON(ctx, apiURL, &sizes); err != nil {
+		return nil, err
+	}
+	return percentages(sizes), nil
+}
+
+func (g *gitHub) ListRepos(ctx context.Context, name string, opts ListOptions) ([]Repo, error) {
+	if strings.Contains(name, "/") {
+		return nil, ErrBadSource
+	}
+	// Пользователь или организация — у них разные эндпоинты
+	var account struct {
+		Login string `json:"login"`
+		Type  string `json:"type"`
+	}
+	if _, err := g.c.getJSON(ctx, fmt.Sprintf("%s/users/%s", g.api, url.PathEscape(name)), &account); err != nil {
+		return nil, err
+	}
+
+	if account.Type == "Organization" {
+		// type=all вернёт и приватные, если токен состоит в организации, —
+		// только когда приватные разрешены, и с одним закреплённым токеном
+		if !opts.Private {
+			return getPages(ctx, g.c,
+				fmt.Sprintf("%s/orgs/%s/repos?type=public&per_page=100", g.api, url.PathEscape(name)), ghRepo.repo)
+		}
+		pc, err := g.c.pinned(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("private repos of %s: %w", name, err)
+		}
+		return getPages(ctx, pc,
+			fmt.Sprintf("%s/orgs/%s/repos?type=all&per_page=100", g.api, url.PathEscape(name)), ghRepo.repo)
+	}
+
+	repos, err := getPages(ctx, g.c,
+		fmt.Sprintf("%s/users/%s/repos?type=owner&per_page=100", g.api, url.PathEscape(name)), ghRepo.repo)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Private {
+		own, perr := g.ownPrivateRepos(ctx, account.Login)
+		if perr != nil {
+			logger.Infof("providers: private repos of %s unavailable: %v", name, perr)
+		}
+		repos = append(repos, own...)
+	}
+	return repos, nil
+}
+
+// ownPrivateRepos — приватные репозитории login через /user/repos. Берётся
+// один токен из пула, и только если он принадлежит самому login: чужой
+// токен (коллаборатора) не должен открывать приватные репозитории аккаунта.
+func (g *gitHub) ownPrivateRepos(ctx context.Context, login string) ([]Repo, error) {
+	pc, err := g.c.pinned(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var me struct {
+		Login string `json:"login"`
+	}
+	if _, err := pc.getJSON(ctx, g.api+"/user", &me); err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(me.Login, login) {
+		return nil, fmt.Errorf("token belongs to %q, not %q", me.Login, login)
+	}
+	return getPages(ctx, pc,
+		fmt.Sprintf("%s/user/repos?visibility=private&affiliation=owner&per_page=100", g.api), ghRepo.repo)
+}
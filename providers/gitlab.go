@@ -0,0 +1,166 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"Site/tokenpool"
+)
+
+// glProject описывает JSON-ответ GitLab API (/projects)
+type glProject struct {
+	Path              string          `json:"path"`
+	PathWithNamespace string          `json:"path_with_namespace"`
+	Description       string          `json:"description"`
+	WebURL            string          `json:"web_url"`
+	AvatarURL         string          `json:"avatar_url"`
+	Archived          bool            `json:"archived"`
+	Visibility        string          `json:"visibility"`
+	ForkedFrom        json.RawMessage `json:"forked_from_project"`
+	Namespace         struct {
+		Path      string `json:"path"`
+		AvatarURL string `json:"avatar_url"`
+	} `json:"namespace"`
+	StarCount      int        `json:"star_count"`
+	ForksCount     int        `json:"forks_count"`
+	Topics         []string   `json:"topics"`
+	LastActivityAt *time.Time `json:"last_activity_at"`
+	DefaultBranch  string     `json:"default_branch"`
+	// License приходит только для одного проекта с ?license=true
+	License *struct {
+		Key      string `json:"key"`
+		Nickname string `json:"nickname"`
+		Name     string `json:"name"`
+	} `json:"license"`
+}
+
+func (p glProject) licenseName() string {
+	switch {
+	case p.License == nil:
+		return ""
+	case p.License.Nickname != "":
+		return p.License.Nickname
+	}
+	return p.License.Name
+}
+
+func (p glProject) repo() Repo {
+	avatar := p.AvatarURL
+	if avatar == "" {
+		avatar = p.Namespace.AvatarURL
+	}
+	return Repo{
+		Name:        p.Path,
+		FullName:    p.PathWithNamespace,
+		Description: p.Description,
+		HTMLURL:     p.WebURL,
+		AvatarURL:   avatar,
+		OwnerLogin:  p.Namespace.Path,
+		Fork:        len(p.ForkedFrom) > 0 && string(p.ForkedFrom) != "null",
+		Archived:    p.Archived,
+		// internal виден только залогиненным — для портфолио это тоже закрытый
+		Private:  p.Visibility != "" && p.Visibility != "public",
+		Stars:    p.StarCount,
+		Forks:    p.ForksCount,
+		Topics:   p.Topics,
+		License:  p.licenseName(),
+		PushedAt: p.LastActivityAt,
+
+		DefaultBranch: p.DefaultBranch,
+	}
+}
+
+// gitLab — gitlab.com или self-hosted GitLab
+type gitLab struct {
+	id, web, api string
+	c            *apiClient
+}
+
+func newGitLab(id, web string) *gitLab {
+	return &gitLab{
+		id:  id,
+		web: web,
+		api: web + "/api/v4",
+		c: newAPIClient(id, newHTTPClient(""), "application/json",
+			func(req *http.Request, token string) { req.Header.Set("PRIVATE-TOKEN", token) }),
+	}
+}
+
+func (g *gitLab) ID() string              { return g.id }
+func (g *gitLab) Kind() string            { return "gitlab" }
+func (g *gitLab) BaseURL() string         { return g.web }
+func (g *gitLab) Tokens() *tokenpool.Pool { return g.c.tokens }
+
+func (g *gitLab) GetRepo(ctx context.Context, path string) (Repo, error) {
+	var p glProject
+	// Путь проекта передаётся целиком, с экранированными "/"
+	if _, err := g.c.getJSON(ctx, fmt.Sprintf("%s/projects/%s?license=true", g.api, url.PathEscape(path)), &p); err != nil {
+		return Repo{}, err
+	}
+	return p.repo(), nil
+}
+
+// Languages — GitLab сразу отдаёт доли в процентах; основной язык
+// в списке проектов не приходит, его выберет withDetails
+func (g *gitLab) Languages(ctx context.Context, repo Repo) (map[string]float64, error) {
+	var langs map[string]float64
+	apiURL := fmt.Sprintf("%s/projects/%s/languages", g.api, url.PathEscape(repo.FullName))
+	if _, err := g.c.getJSON(ctx, apiURL, &langs); err != nil {
+		return nil, err
+	}
+	return langs, nil
+}
+
+// Readme — files API по перебираемым именам; корни строятся от web_url
+// (/-/raw/{branch}/ и /-/blob/{branch}/)
+func (g *gitLab) Readme(ctx context.Context, repo Repo) (*Readme, error) {
+	if repo.DefaultBranch == "" {
+		return &Readme{}, nil
+	}
+	c := g.c.forRepo(repo)
+	return firstReadme(func(file string) (*Readme, error) {
+		var f fileContent
+		apiURL := fmt.Sprintf("%s/projects/%s/repository/files/%s?ref=%s", g.api,
+			url.PathEscape(repo.FullName), url.PathEscape(file), url.QueryEscape(repo.DefaultBranch))
+		if _, err := c.getJSON(ctx, apiURL, &f); err != nil {
+			return nil, err
+		}
+		text, err := f.text()
+		if err != nil {
+			return nil, err
+		}
+		return &Readme{
+			Path:    f.FilePath,
+			Content: text,
+			RawRoot: repo.HTMLURL + "/-/raw/" + repo.DefaultBranch + "/",
+			WebRoot: repo.HTMLURL + "/-/blob/" + repo.DefaultBranch + "/",
+		}, nil
+	})
+}
+
+// ListRepos: account — группа (в том числе вложенная) или пользователь
+func (g *gitLab) ListRepos(ctx context.Context, account string, opts ListOptions) ([]Repo, error) {
+	repos, err := getPages(ctx, g.c,
+		fmt.Sprintf("%s/groups/%s/projects?include_subgroups=true&per_page=100", g.api, url.PathEscape(account)),
+		glProject.repo)
+	if err == nil || !IsNotFound(err) {
+		return repos, err
+	}
+
+	// Не группа — ищем пользователя
+	var users []struct {
+		ID int `json:"id"`
+	}
+	if _, err := g.c.getJSON(ctx, fmt.Sprintf("%s/users?username=%s", g.api, url.QueryEscape(account)), &users); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, &APIError{Provider: g.id, Status: http.StatusNotFound, Body: "user or group " + account + " not found"}
+	}
+	return getPages(ctx, g.c,
+		fmt.Sprintf("%s/users/%d/projects?per_page=100", g.api, users[0].ID), glProject.repo)
+}
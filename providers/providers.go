@@ -0,0 +1,364 @@
+// Package providers — импорт репозиториев с хостингов кода
+// (GitHub, GitLab, Gitea/Forgejo, Bitbucket) через общий интерфейс.
+//
+// Каждый экземпляр провайдера определяется хостом веб-интерфейса
+// ("github.com", "gitlab.example.com"); этот же хост служит ключом
+// пула токенов и значением projects.provider.
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"Site/logger"
+	"Site/tokenpool"
+)
+
+// DefaultProvider — хост, на который указывают источники без URL ("username")
+const DefaultProvider = "github.com"
+
+// ErrBadSource — источник не удалось разобрать
+var ErrBadSource = errors.New("неверный формат источника")
+
+// APIError — хостинг ответил ошибкой
+type APIError struct {
+	Provider string
+	Status   int
+	Body     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API %d: %s", e.Provider, e.Status, e.Body)
+}
+
+// IsNotFound — ошибка 404 от API
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// Repo — репозиторий в независимом от провайдера виде
+type Repo struct {
+	Name        string
+	FullName    string
+	Description string
+	HTMLURL     string
+	AvatarURL   string
+	OwnerLogin  string
+	Fork        bool
+	Archived    bool
+	Private     bool
+	Stars       int
+	Forks       int
+	// Language — основной язык; Languages — доли языков в процентах
+	Language  string
+	Languages map[string]float64
+	Topics    []string
+	Homepage  string
+	// License — SPDX-идентификатор (MIT, Apache-2.0) или название
+	License  string
+	PushedAt *time.Time
+	// DefaultBranch — ветка, из которой берётся README
+	DefaultBranch string
+	// Readme: nil — неизвестно (ошибка или провайдер не умеет),
+	// пустой Content — README в репозитории нет
+	Readme *Readme
+}
+
+// ListOptions — фильтры списка репозиториев пользователя/группы
+type ListOptions struct {
+	Forks    bool
+	Archived bool
+	// Private — добавлять приватные репозитории, если токен имеет к ним доступ
+	Private bool
+}
+
+// Provider — экземпляр хостинга кода
+type Provider interface {
+	// ID — хост веб-интерфейса, ключ пула токенов и projects.provider
+	ID() string
+	// Kind — тип API: github, gitlab, gitea, bitbucket
+	Kind() string
+	// BaseURL — адрес веб-интерфейса (https://github.com)
+	BaseURL() string
+	// Tokens — пул токенов этого экземпляра
+	Tokens() *tokenpool.Pool
+	// ListRepos — репозитории пользователя, организации или группы
+	ListRepos(ctx context.Context, account string, opts ListOptions) ([]Repo, error)
+	// GetRepo — один репозиторий по пути "owner/repo" (у GitLab — с подгруппами)
+	GetRepo(ctx context.Context, path string) (Repo, error)
+}
+
+// LanguageLister — провайдер умеет отдавать разбивку репозитория по языкам
+// (отдельный запрос на каждый репозиторий, поэтому не входит в Repo из списка)
+type LanguageLister interface {
+	Languages(ctx context.Context, repo Repo) (map[string]float64, error)
+}
+
+// Fetch загружает репозитории по пути источника: один сегмент — аккаунт,
+// несколько — сначала пробуем как репозиторий, а на 404 как группу
+// (у GitLab "group/subgroup" и "group/project" выглядят одинаково).
+// Репозиторий, не прошедший фильтры opts, считается ненайденным.
+func Fetch(ctx context.Context, p Provider, path string, opts ListOptions) ([]Repo, error) {
+	if !strings.Contains(path, "/") {
+		repos, err := p.ListRepos(ctx, path, opts)
+		if err != nil {
+			return nil, err
+		}
+		return withDetails(ctx, p, Filter(repos, opts)), nil
+	}
+	repo, err := p.GetRepo(ctx, path)
+	if err == nil {
+		// Фильтры те же, что у списка: пул токенов видит и приватные
+		// репозитории, а без opts.Private они не импортируются
+		repos := Filter([]Repo{repo}, opts)
+		if len(repos) == 0 {
+			return nil, &APIError{Provider: p.ID(), Status: http.StatusNotFound,
+				Body: "repository " + path + " not found or not allowed by import options"}
+		}
+		return withDetails(ctx, p, repos), nil
+	}
+	if !IsNotFound(err) {
+		return nil, err
+	}
+	repos, lerr := p.ListRepos(ctx, path, opts)
+	if lerr != nil {
+		// Ни репозитория, ни группы — показываем исходную ошибку
+		return nil, err
+	}
+	return withDetails(ctx, p, Filter(repos, opts)), nil
+}
+
+// withDetails дозагружает разбивку по языкам и README. Ошибка по одному
+// репозиторию не срывает импорт — карточка просто останется без них.
+func withDetails(ctx context.Context, p Provider, repos []Repo) []Repo {
+	ll, _ := p.(LanguageLister)
+	rf, _ := p.(ReadmeFetcher)
+	for i := range repos {
+		if ctx.Err() != nil {
+			break
+		}
+		if ll != nil {
+			langs, err := ll.Languages(ctx, repos[i])
+			if err != nil {
+				logger.Infof("providers: languages of %s/%s unavailable: %v", p.ID(), repos[i].FullName, err)
+			} else {
+				repos[i].Languages = langs
+				if repos[i].Language == "" {
+					repos[i].Language = mainLanguage(langs)
+				}
+			}
+		}
+		if rf != nil {
+			rd, err := rf.Readme(ctx, repos[i])
+			if err != nil {
+				logger.Infof("providers: README of %s/%s unavailable: %v", p.ID(), repos[i].FullName, err)
+			} else {
+				repos[i].Readme = rd
+			}
+		}
+	}
+	return repos
+}
+
+// percentages переводит размеры в байтах (GitHub, Gitea) в доли в процентах
+func percentages(sizes map[string]float64) map[string]float64 {
+	var total float64
+	for _, n := range sizes {
+		total += n
+	}
+	out := make(map[string]float64, len(sizes))
+	if total <= 0 {
+		return out
+	}
+	for lang, n := range sizes {
+		out[lang] = math.Round(n/total*1000) / 10
+	}
+	return out
+}
+
+// mainLanguage — язык с наибольшей долей
+func mainLanguage(langs map[string]float64) string {
+	best, share := "", -1.0
+	for lang, v := range langs {
+		if v > share || (v == share && lang < best) {
+			best, share = lang, v
+		}
+	}
+	return best
+}
+
+// Filter применяет фильтры и убирает дубликаты по имени
+func Filter(repos []Repo, opts ListOptions) []Repo {
+	seen := map[string]bool{}
+	out := repos[:0]
+	for _, r := range repos {
+		if seen[r.Name] ||
+			(r.Fork && !opts.Forks) ||
+			(r.Archived && !opts.Archived) ||
+			(r.Private && !opts.Private) {
+			continue
+		}
+		seen[r.Name] = true
+		out = append(out, r)
+	}
+	return out
+}
+
+// Registry — набор настроенных экземпляров провайдеров
+type Registry struct {
+	byID map[string]Provider
+}
+
+// NewRegistry собирает реестр из готовых провайдеров (удобно для тестов)
+func NewRegistry(list ...Provider) *Registry {
+	r := &Registry{byID: map[string]Provider{}}
+	for _, p := range list {
+		r.byID[p.ID()] = p
+	}
+	return r
+}
+
+// Get возвращает провайдер по хосту
+func (r *Registry) Get(id string) Provider {
+	return r.byID[strings.ToLower(id)]
+}
+
+// List — все провайдеры, отсортированные по хосту
+func (r *Registry) List() []Provider {
+	list := make([]Provider, 0, len(r.byID))
+	for _, p := range r.byID {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID() < list[j].ID() })
+	return list
+}
+
+// Resolve разбирает ввод пользователя: ссылку на любой настроенный хостинг
+// или просто путь ("username", "owner/repo") — тогда это GitHub.
+// Возвращает провайдер и нормализованный путь без ведущих/хвостовых "/" и ".git".
+func (r *Registry) Resolve(raw string) (Provider, string, error) {
+	raw = strings.TrimSpace(raw)
+	id := DefaultProvider
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		id = strings.ToLower(u.Host)
+		raw = u.Path
+	} else if host, rest, ok := strings.Cut(raw, "/"); ok && strings.Contains(host, ".") {
+		// "gitlab.com/group/project" без схемы
+		id = strings.ToLower(host)
+		raw = rest
+	}
+	p := r.Get(id)
+	if p == nil {
+		return nil, "", fmt.Errorf("%w: хостинг %s не настроен", ErrBadSource, id)
+	}
+	path := strings.TrimSuffix(strings.Trim(raw, "/"), ".git")
+	if path == "" {
+		return nil, "", ErrBadSource
+	}
+	for _, seg := range strings.Split(path, "/") {
+		if seg == "" {
+			return nil, "", ErrBadSource
+		}
+	}
+	return p, path, nil
+}
+
+// newHTTPClient — клиент с таймаутом и, при необходимости, прокси
+func newHTTPClient(proxy string) *http.Client {
+	if strings.TrimSpace(proxy) != "" {
+		if proxyURL, errp := url.Parse(proxy); errp == nil {
+			transport := &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+			return &http.Client{Transport: transport, Timeout: 30 * time.Second}
+		}
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// Option — настройка провайдера при создании
+type Option func(api *string, c *apiClient)
+
+// WithAPI подменяет адрес API (например, фейковым сервером в тестах)
+func WithAPI(api string) Option {
+	return func(a *string, _ *apiClient) { *a = strings.TrimRight(api, "/") }
+}
+
+// WithHTTPClient задаёт HTTP-клиент для запросов к API
+func WithHTTPClient(hc *http.Client) Option {
+	return func(_ *string, c *apiClient) { c.http = hc }
+}
+
+// New создаёт провайдер нужного типа для веб-адреса base
+func New(kind, base string, opts ...Option) (Provider, error) {
+	u, err := url.Parse(strings.TrimRight(strings.TrimSpace(base), "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("provider %s: bad base url %q", kind, base)
+	}
+	host := strings.ToLower(u.Host)
+	web := u.Scheme + "://" + u.Host + strings.TrimRight(u.Path, "/")
+	var (
+		p   Provider
+		api *string
+		c   *apiClient
+	)
+	switch kind {
+	case "github":
+		g := newGitHub(host, web)
+		p, api, c = g, &g.api, g.c
+	case "gitlab":
+		g := newGitLab(host, web)
+		p, api, c = g, &g.api, g.c
+	case "gitea", "forgejo":
+		g := newGitea(host, web)
+		p, api, c = g, &g.api, g.c
+	case "bitbucket":
+		if host != "bitbucket.org" {
+			return nil, fmt.Errorf("provider bitbucket: only Bitbucket Cloud (bitbucket.org) is supported")
+		}
+		b := newBitbucket(host, web)
+		p, api, c = b, &b.api, b.c
+	default:
+		return nil, fmt.Errorf("provider: unknown kind %q", kind)
+	}
+	for _, opt := range opts {
+		opt(api, c)
+	}
+	return p, nil
+}
+
+// FromEnv собирает реестр: встроенные публичные хостинги плюс
+// самостоятельно размещённые из GIT_PROVIDERS, например
+//
+//	GIT_PROVIDERS=gitlab=https://git.example.com,gitea=https://gitea.example.com
+func FromEnv() (*Registry, error) {
+	list := []Provider{
+		newGitHub("github.com", "https://github.com"),
+		newGitLab("gitlab.com", "https://gitlab.com"),
+		newGitea("codeberg.org", "https://codeberg.org"),
+		newBitbucket("bitbucket.org", "https://bitbucket.org"),
+	}
+	for _, item := range strings.Split(os.Getenv("GIT_PROVIDERS"), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kind, base, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("GIT_PROVIDERS: expected kind=url, got %q", item)
+		}
+		p, err := New(strings.ToLower(strings.TrimSpace(kind)), base)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return NewRegistry(list...), nil
+}
@@ -0,0 +1,101 @@
+package providers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Readme — README репозитория и корни, от которых считаются его
+// относительные ссылки: картинки грузятся с raw-хоста, переходы по
+// ссылкам ведут в веб-интерфейс хостинга.
+type Readme struct {
+	// Path — путь файла в репозитории ("README.md", "docs/README.md")
+	Path    string
+	Content string
+	// RawRoot — корень репозитория на raw-хосте, со слэшем на конце
+	// (https://raw.githubusercontent.com/owner/repo/main/)
+	RawRoot string
+	// WebRoot — корень дерева файлов в веб-интерфейсе, со слэшем на конце
+	// (https://github.com/owner/repo/blob/main/)
+	WebRoot string
+}
+
+// ReadmeFetcher — провайдер умеет отдавать README репозитория.
+// Если README нет, возвращает &Readme{} без ошибки.
+type ReadmeFetcher interface {
+	Readme(ctx context.Context, repo Repo) (*Readme, error)
+}
+
+// readmeNames — имена, которые перебираем там, где API не ищет README сам
+var readmeNames = []string{"README.md", "readme.md", "Readme.md", "README.markdown", "README", "README.txt"}
+
+// firstReadme перебирает readmeNames, пока fetch не найдёт файл
+func firstReadme(fetch func(name string) (*Readme, error)) (*Readme, error) {
+	for _, name := range readmeNames {
+		rd, err := fetch(name)
+		if IsNotFound(err) {
+			continue
+		}
+		return rd, err
+	}
+	return &Readme{}, nil
+}
+
+// fileContent — ответ contents API GitHub и Gitea (и files API GitLab)
+type fileContent struct {
+	Path        string `json:"path"`
+	FilePath    string `json:"file_path"`
+	Content     string `json:"content"`
+	Encoding    string `json:"encoding"`
+	DownloadURL string `json:"download_url"`
+	HTMLURL     string `json:"html_url"`
+}
+
+func (f fileContent) text() (string, error) {
+	if f.Encoding != "base64" {
+		return f.Content, nil
+	}
+	// GitHub переносит base64 по 60 символов
+	b, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(f.Content, "\n", ""))
+	if err != nil {
+		return "", fmt.Errorf("README decode: %w", err)
+	}
+	return string(b), nil
+}
+
+// contentReadme собирает Readme из ответа contents API: корни получаются
+// отрезанием пути файла от download_url и html_url
+func contentReadme(f fileContent) (*Readme, error) {
+	text, err := f.text()
+	if err != nil {
+		return nil, err
+	}
+	return &Readme{
+		Path:    f.Path,
+		Content: text,
+		RawRoot: fileRoot(f.DownloadURL, f.Path),
+		WebRoot: fileRoot(f.HTMLURL, f.Path),
+	}, nil
+}
+
+// fileRoot отрезает путь файла (и query, например токен приватного
+// репозитория) от его URL
+func fileRoot(fileURL, filePath string) string {
+	u, err := url.Parse(fileURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	u.RawQuery, u.Fragment = "", ""
+	s := u.String()
+	if root, ok := strings.CutSuffix(s, filePath); ok {
+		return root
+	}
+	if root, ok := strings.CutSuffix(s, (&url.URL{Path: filePath}).EscapedPath()); ok {
+		return root
+	}
+	// Путь не совпал — хотя бы каталог файла
+	return s[:strings.LastIndexByte(s, '/')+1]
+}
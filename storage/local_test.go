@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLocalPutMode: загрузки должны читаться не только владельцем
+// процесса (веб-сервер, бэкап), как было при os.Create
+func TestLocalPutMode(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLocal(dir, "/uploads/")
+	const key, body = "a1b2c3.png", "png"
+	if err := l.Put(context.Background(), key, strings.NewReader(body), int64(len(body)), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	fi, err := os.Stat(filepath.Join(dir, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := fi.Mode().Perm(); mode != 0o644 {
+		t.Errorf("mode = %o, want 644", mode)
+	}
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config — параметры S3-совместимого хранилища, см. FromEnv
+type S3Config struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// Prefix — «каталог» ключей внутри бакета, например uploads/
+	Prefix string
+	// PublicURL — откуда браузер берёт объекты напрямую (бакет или CDN);
+	// пустой — отдаёт сайт по URLPrefix через Handler
+	PublicURL string
+	URLPrefix string
+}
+
+// S3 — объекты в бакете S3-совместимого хранилища
+type S3 struct {
+	client *minio.Client
+	cfg    S3Config
+}
+
+// NewS3 подключается к хранилищу и проверяет, что бакет существует
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("storage: для STORAGE=s3 нужны S3_ENDPOINT и S3_BUCKET")
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: s3 client: %w", err)
+	}
+	ok, err := client.BucketExists(context.Background(), cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("storage: s3 bucket %q: %w", cfg.Bucket, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("storage: s3 bucket %q не существует", cfg.Bucket)
+	}
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+	return &S3{client: client, cfg: cfg}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.PutObject(ctx, s.cfg.Bucket, s.cfg.Prefix+key, r, size, minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: "public, max-age=31536000, immutable",
+	})
+	return err
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.cfg.Bucket, s.cfg.Prefix+key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.mapErr(err)
+	}
+	// GetObject ленивый: об отсутствии объекта узнаём только при Stat/Read
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, s.mapErr(err)
+	}
+	return obj, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.cfg.Bucket, s.cfg.Prefix+key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3) List(ctx context.Context) ([]Object, error) {
+	var objs []Object
+	for info := range s.client.ListObjects(ctx, s.cfg.Bucket, minio.ListObjectsOptions{
+		Prefix:    s.cfg.Prefix,
+		Recursive: true,
+	}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		key := strings.TrimPrefix(info.Key, s.cfg.Prefix)
+		if validKey(key) != nil {
+			continue
+		}
+		objs = append(objs, Object{Key: key, ModTime: info.LastModified})
+	}
+	return objs, nil
+}
+
+func (s *S3) URL(key string) string {
+	if s.cfg.PublicURL != "" {
+		return s.cfg.PublicURL + "/" + s.cfg.Prefix + key
+	}
+	return s.cfg.URLPrefix + key
+}
+
+func (s *S3) mapErr(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestS3RoundTrip проверяет S3 против настоящего хранилища, например
+// локального MinIO:
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	S3_ENDPOINT=localhost:9000 S3_BUCKET=test S3_USE_SSL=false \
+//	S3_ACCESS_KEY=minioadmin S3_SECRET_KEY=minioadmin go test ./storage
+//
+// Бакет должен существовать; объекты пишутся под отдельным префиксом и
+// удаляются тестом. Без S3_ENDPOINT тест пропускается.
+func TestS3RoundTrip(t *testing.T) {
+	if os.Getenv("S3_ENDPOINT") == "" {
+		t.Skip("S3_ENDPOINT is not set")
+	}
+	ctx := context.Background()
+	prefix := fmt.Sprintf("storage-test-%d/", time.Now().UnixNano())
+	t.Setenv("STORAGE", "s3")
+	t.Setenv("S3_PREFIX", prefix)
+	t.Setenv("S3_PUBLIC_URL", "")
+
+	b, err := FromEnv(t.TempDir(), "/uploads/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok := b.(*S3)
+	if !ok {
+		t.Fatalf("FromEnv returned %T, want *S3", b)
+	}
+
+	const key, body = "a1b2c3.png", "not really a png"
+	if err := s.Put(ctx, key, strings.NewReader(body), int64(len(body)), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	t.Cleanup(func() { s.Delete(context.Background(), key) })
+
+	rc, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || string(got) != body {
+		t.Fatalf("Get = %q, %v; want %q", got, err, body)
+	}
+
+	objs, err := s.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objs) != 1 || objs[0].Key != key {
+		t.Fatalf("List = %+v, want only %s", objs, key)
+	}
+
+	if u := s.URL(key); u != "/uploads/"+key {
+		t.Errorf("URL without public URL = %q, want site path", u)
+	}
+	s.cfg.PublicURL = "https://cdn.example.com"
+	if u, want := s.URL(key), "https://cdn.example.com/"+prefix+key; u != want {
+		t.Errorf("URL with public URL = %q, want %q", u, want)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete: %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of missing object: %v, want nil", err)
+	}
+	if err := s.Put(ctx, "../escape", strings.NewReader(""), 0, ""); err == nil {
+		t.Error("Put accepted a key with a path")
+	}
+}
@@ -0,0 +1,139 @@
+// Package storage — где лежат загруженные файлы.
+//
+// Backend скрывает, диск это или S3-совместимое хранилище (AWS S3, MinIO,
+// Yandex Object Storage…): на диске файлы видит только тот инстанс, который
+// их принял, а с двумя инстансами за балансировщиком нужно общее хранилище.
+// Ключи — плоские имена файлов без каталогов.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotFound — объекта с таким ключом нет
+var ErrNotFound = errors.New("storage: объект не найден")
+
+// Object — запись листинга
+type Object struct {
+	Key     string
+	ModTime time.Time
+}
+
+// Backend — хранилище загруженных файлов
+type Backend interface {
+	// Put записывает объект целиком (существующий заменяется)
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект на чтение; ErrNotFound — если его нет
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект; отсутствующий объект — не ошибка
+	Delete(ctx context.Context, key string) error
+	// List перечисляет все объекты (для уборки сирот)
+	List(ctx context.Context) ([]Object, error)
+	// URL — публичный адрес объекта
+	URL(key string) string
+}
+
+// validKey не пускает в ключи каталоги и «..»
+func validKey(key string) error {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." {
+		return fmt.Errorf("storage: недопустимый ключ %q", key)
+	}
+	return nil
+}
+
+// FromEnv создаёт хранилище по настройкам окружения:
+//
+//	STORAGE       -> local (по умолчанию) или s3
+//	S3_ENDPOINT   -> host[:port] без схемы, например minio:9000
+//	S3_BUCKET     -> бакет
+//	S3_REGION     -> регион (необязательно)
+//	S3_ACCESS_KEY, S3_SECRET_KEY -> ключи доступа
+//	S3_USE_SSL    -> "0"/"false" — ходить по http (локальный MinIO)
+//	S3_PREFIX     -> префикс ключей в бакете (по умолчанию uploads/)
+//	S3_PUBLIC_URL -> публичный адрес бакета/CDN; без него файлы отдаёт
+//	                 сам сайт через urlPrefix
+//
+// Локальное хранилище — каталог dir, раздаётся по urlPrefix.
+func FromEnv(dir, urlPrefix string) (Backend, error) {
+	switch kind := strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE"))); kind {
+	case "", "local":
+		return NewLocal(dir, urlPrefix), nil
+	case "s3":
+		useSSL := true
+		switch strings.ToLower(strings.TrimSpace(os.Getenv("S3_USE_SSL"))) {
+		case "0", "false", "no", "off":
+			useSSL = false
+		}
+		prefix, ok := os.LookupEnv("S3_PREFIX")
+		if !ok {
+			prefix = "uploads/"
+		}
+		return NewS3(S3Config{
+			Endpoint:  strings.TrimSpace(os.Getenv("S3_ENDPOINT")),
+			Bucket:    strings.TrimSpace(os.Getenv("S3_BUCKET")),
+			Region:    strings.TrimSpace(os.Getenv("S3_REGION")),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			UseSSL:    useSSL,
+			Prefix:    prefix,
+			PublicURL: strings.TrimSpace(os.Getenv("S3_PUBLIC_URL")),
+			URLPrefix: urlPrefix,
+		})
+	default:
+		return nil, fmt.Errorf("storage: неизвестный STORAGE %q (local|s3)", kind)
+	}
+}
+
+// Handler раздаёт объекты по ключу из пути (подключается под StripPrefix).
+// Локальный диск отдаётся http.FileServer, остальное проксируется через Get.
+// Имена загрузок — хэши содержимого, поэтому кэшировать можно навсегда.
+func Handler(b Backend) http.Handler {
+	if l, ok := b.(*Local); ok {
+		return http.FileServer(http.Dir(l.Dir))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if validKey(key) != nil {
+			http.NotFound(w, r)
+			return
+		}
+		rc, err := b.Get(r.Context(), key)
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Storage error", http.StatusBadGateway)
+			return
+		}
+		defer rc.Close()
+		if ct := contentType(key); ct != "" {
+			w.Header().Set("Content-Type", ct)
+		}
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		io.Copy(w, rc)
+	})
+}
+
+// contentType — тип по расширению для тех форматов, что сохраняет uploads
+func contentType(key string) string {
+	switch {
+	case strings.HasSuffix(key, ".jpg"), strings.HasSuffix(key, ".jpeg"):
+		return "image/jpeg"
+	case strings.HasSuffix(key, ".png"):
+		return "image/png"
+	case strings.HasSuffix(key, ".gif"):
+		return "image/gif"
+	case strings.HasSuffix(key, ".webp"):
+		return "image/webp"
+	}
+	return ""
+}
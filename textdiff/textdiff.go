@@ -0,0 +1,176 @@
+// Package textdiff — построчное сравнение текстов (алгоритм Майерса,
+// «An O(ND) Difference Algorithm and Its Variations»): кратчайший набор
+// вставок и удалений, как у diff -u, только без контекстных блоков.
+package textdiff
+
+import "strings"
+
+// Op — что случилось со строкой
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Symbol — знак строки в unified diff: " ", "+" или "-"
+func (o Op) Symbol() string {
+	switch o {
+	case Insert:
+		return "+"
+	case Delete:
+		return "-"
+	}
+	return " "
+}
+
+// Line — строка результата. Номера строк начинаются с 1; у вставленной
+// строки нет OldNo, у удалённой — NewNo (там 0).
+type Line struct {
+	Op    Op
+	Text  string
+	OldNo int
+	NewNo int
+}
+
+// Lines сравнивает тексты a и b построчно
+func Lines(a, b string) []Line {
+	return diff(split(a), split(b))
+}
+
+// Changed — есть ли в результате вставки или удаления
+func Changed(lines []Line) bool {
+	for _, l := range lines {
+		if l.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// split режет текст на строки; \r\n считается одним переводом строки,
+// завершающий перевод строки не даёт пустой последней строки
+func split(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func diff(a, b []string) []Line {
+	// Общие начало и конец в поиск не идут — обычная правка трогает
+	// несколько строк в середине длинного текста
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	out := make([]Line, 0, len(a)+len(b)-pre-suf)
+	for i := 0; i < pre; i++ {
+		out = append(out, Line{Op: Equal, Text: a[i], OldNo: i + 1, NewNo: i + 1})
+	}
+	for _, l := range myers(a[pre:len(a)-suf], b[pre:len(b)-suf]) {
+		if l.OldNo > 0 {
+			l.OldNo += pre
+		}
+		if l.NewNo > 0 {
+			l.NewNo += pre
+		}
+		out = append(out, l)
+	}
+	for i := suf; i > 0; i-- {
+		out = append(out, Line{Op: Equal, Text: a[len(a)-i], OldNo: len(a) - i + 1, NewNo: len(b) - i + 1})
+	}
+	return out
+}
+
+// maxEdits — предел длины пути правок: дальше тексты считаются
+// разными целиком, чтобы не тратить на сохранённые фронты O(D²) памяти
+const maxEdits = 2000
+
+// myers — жадный проход по диагоналям с сохранением фронтов V на каждом
+// шаге d, затем обратный проход по сохранённым фронтам
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	off := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] — диагонали -d-1…d+1 фронта перед шагом d
+	var trace [][]int
+	for d := 0; d <= max && d <= maxEdits; d++ {
+		trace = append(trace, append([]int(nil), v[off-d-1:off+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1] // шаг вниз: вставка
+			} else {
+				x = v[off+k-1] + 1 // шаг вправо: удаление
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return replaceAll(a, b)
+}
+
+func backtrack(a, b []string, trace [][]int) []Line {
+	x, y := len(a), len(b)
+	var rev []Line
+	for d := len(trace) - 1; d >= 0; d-- {
+		// at(k) — значение диагонали k во фронте trace[d]
+		at := func(k int) int { return trace[d][k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			rev = append(rev, Line{Op: Equal, Text: a[x-1], OldNo: x, NewNo: y})
+			x, y = x-1, y-1
+		}
+		if d > 0 {
+			if x == prevX {
+				rev = append(rev, Line{Op: Insert, Text: b[y-1], NewNo: y})
+			} else {
+				rev = append(rev, Line{Op: Delete, Text: a[x-1], OldNo: x})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	out := make([]Line, len(rev))
+	for i, l := range rev {
+		out[len(rev)-1-i] = l
+	}
+	return out
+}
+
+// replaceAll — все строки a удалены, все строки b вставлены
+func replaceAll(a, b []string) []Line {
+	out := make([]Line, 0, len(a)+len(b))
+	for i, s := range a {
+		out = append(out, Line{Op: Delete, Text: s, OldNo: i + 1})
+	}
+	for i, s := range b {
+		out = append(out, Line{Op: Insert, Text: s, NewNo: i + 1})
+	}
+	return out
+}
@@ -0,0 +1,276 @@
+// Package tokenpool — пул токенов API с учётом лимитов.
+//
+// Токены лежат в github_tokens, у каждого провайдера (хоста) свой пул.
+// Для каждого токена хранится остаток квоты и время её
+// сброса (X-RateLimit-Remaining / X-RateLimit-Reset), пауза после
+// вторичного лимита (Retry-After) и счётчик подряд идущих 401.
+// Исчерпанные токены пропускаются до сброса и возвращаются в ротацию
+// автоматически; выключаются только токены с неверными учётными данными.
+package tokenpool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Site/db"
+	"Site/logger"
+	"Site/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNoToken — в пуле нет токена, доступного прямо сейчас
+var ErrNoToken = errors.New("tokenpool: no usable token")
+
+// MaxAuthFailures — после стольких 401 подряд токен выключается
+const MaxAuthFailures = 3
+
+// secondaryCooldown — пауза, если вторичный лимит пришёл без Retry-After
+// (так советует документация GitHub)
+const secondaryCooldown = time.Minute
+
+// Outcome — как ответ API повлиял на токен
+type Outcome int
+
+const (
+	// OK — ответ можно использовать (в том числе 404 и прочие «честные» ошибки)
+	OK Outcome = iota
+	// RateLimited — квота исчерпана, токен на паузе до сброса
+	RateLimited
+	// SecondaryLimited — вторичный лимит, токен на паузе по Retry-After
+	SecondaryLimited
+	// BadCredentials — 401, токен недействителен
+	BadCredentials
+)
+
+// Retry — стоит ли повторить запрос с другим токеном
+func (o Outcome) Retry() bool { return o != OK }
+
+// Pool — пул токенов одного провайдера. Состояние хранится в БД,
+// поэтому несколько инстансов сервера делят одну квоту.
+type Pool struct {
+	provider string
+	now      func() time.Time
+}
+
+// New создаёт пул токенов провайдера (хост, например "github.com")
+func New(provider string) *Pool {
+	return &Pool{provider: provider, now: time.Now}
+}
+
+// Provider — хост, к которому относится пул
+func (p *Pool) Provider() string { return p.provider }
+
+// Acquire выбирает наименее недавно использованный токен, который включён,
+// не на паузе и не исчерпал квоту (или у которого квота уже сбросилась).
+func (p *Pool) Acquire(ctx context.Context) (*models.GitHubToken, error) {
+	var t models.GitHubToken
+	err := db.Pool.QueryRow(ctx, `
+        UPDATE github_tokens SET last_used_at = NOW()
+         WHERE id = (
+            SELECT id
+              FROM github_tokens
+             WHERE enabled = true AND provider = $1
+               AND (cooldown_until IS NULL OR cooldown_until <= NOW())
+               AND (rate_remaining IS NULL OR rate_remaining > 0
+                    OR rate_reset_at IS NULL OR rate_reset_at <= NOW())
+             ORDER BY COALESCE(last_used_at, to_timestamp(0)) ASC, id ASC
+             LIMIT 1
+             FOR UPDATE SKIP LOCKED)
+        RETURNING id, token`, p.provider).Scan(&t.ID, &t.Token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Enabled = true
+	t.Provider = p.provider
+	return &t, nil
+}
+
+// state — что нужно записать о токене после ответа
+type state struct {
+	outcome   Outcome
+	limit     *int
+	remaining *int
+	resetAt   *time.Time
+	cooldown  *time.Time
+	reason    string
+}
+
+// headerInt читает первый из заголовков keys как число
+// (GitHub шлёт X-RateLimit-*, GitLab — RateLimit-*)
+func headerInt(h http.Header, keys ...string) *int {
+	v := ""
+	for _, k := range keys {
+		if v = strings.TrimSpace(h.Get(k)); v != "" {
+			break
+		}
+	}
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+// classify разбирает ответ. body — начало тела (для 403/429), по нему
+// отличаем вторичный лимит от обычного «доступ запрещён».
+func classify(resp *http.Response, body []byte, now time.Time) state {
+	st := state{
+		limit:     headerInt(resp.Header, "X-RateLimit-Limit", "RateLimit-Limit"),
+		remaining: headerInt(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining"),
+	}
+	if reset := headerInt(resp.Header, "X-RateLimit-Reset", "RateLimit-Reset"); reset != nil {
+		t := time.Unix(int64(*reset), 0)
+		st.resetAt = &t
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		st.outcome = BadCredentials
+		st.reason = "bad credentials"
+		return st
+	case http.StatusForbidden, http.StatusTooManyRequests:
+	default:
+		return st
+	}
+
+	// Вторичный лимит: Retry-After (секунды) или упоминание в теле
+	if ra := headerInt(resp.Header, "Retry-After"); ra != nil {
+		t := now.Add(time.Duration(*ra) * time.Second)
+		st.outcome, st.cooldown = SecondaryLimited, &t
+		st.reason = "secondary rate limit (Retry-After " + strconv.Itoa(*ra) + "s)"
+		return st
+	}
+	if bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit")) {
+		t := now.Add(secondaryCooldown)
+		st.outcome, st.cooldown = SecondaryLimited, &t
+		st.reason = "secondary rate limit"
+		return st
+	}
+	// Первичный лимит: квота кончилась, ждём до X-RateLimit-Reset
+	if st.remaining != nil && *st.remaining == 0 {
+		st.outcome = RateLimited
+		st.cooldown = st.resetAt
+		st.reason = "rate limit exhausted"
+		return st
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		t := now.Add(secondaryCooldown)
+		st.outcome, st.cooldown = SecondaryLimited, &t
+		st.reason = "too many requests"
+	}
+	// Иначе это настоящий 403 (нет доступа к ресурсу) — токен ни при чём
+	return st
+}
+
+// Report записывает состояние токена по ответу API и сообщает, нужно ли
+// повторить запрос с другим токеном. Тело 403/429 читается и
+// подменяется копией, так что resp остаётся пригодным для вызывающего.
+func (p *Pool) Report(ctx context.Context, tok *models.GitHubToken, resp *http.Response) Outcome {
+	var body []byte
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	st := classify(resp, body, p.now())
+
+	var err error
+	switch st.outcome {
+	case BadCredentials:
+		_, err = db.Pool.Exec(ctx, `
+            UPDATE github_tokens
+               SET fail_count = fail_count + 1,
+                   enabled = fail_count + 1 < $2,
+                   last_status = $3, last_error = $4, checked_at = NOW()
+             WHERE id = $1`, tok.ID, MaxAuthFailures, resp.StatusCode, st.reason)
+	default:
+		_, err = db.Pool.Exec(ctx, `
+            UPDATE github_tokens
+               SET rate_limit     = COALESCE($2, rate_limit),
+                   rate_remaining = COALESCE($3, rate_remaining),
+                   rate_reset_at  = COALESCE($4, rate_reset_at),
+                   cooldown_until = $5,
+                   fail_count     = CASE WHEN $6 THEN 0 ELSE fail_count END,
+                   last_status = $7, last_error = $8, checked_at = NOW()
+             WHERE id = $1`,
+			tok.ID, st.limit, st.remaining, st.resetAt, st.cooldown,
+			st.outcome == OK, resp.StatusCode, st.reason)
+	}
+	if err != nil {
+		logger.Errorf("tokenpool: report token %d error: %v", tok.ID, err)
+	}
+	if st.outcome != OK {
+		logger.Infof("tokenpool: token %d %s", tok.ID, st.reason)
+	}
+	return st.outcome
+}
+
+// ReportError — сетевая ошибка: токен не виноват, просто отмечаем её
+func (p *Pool) ReportError(ctx context.Context, tok *models.GitHubToken, reqErr error) {
+	if _, err := db.Pool.Exec(ctx,
+		`UPDATE github_tokens SET last_error = $2, checked_at = NOW() WHERE id = $1`,
+		tok.ID, reqErr.Error()); err != nil {
+		logger.Errorf("tokenpool: report token %d error: %v", tok.ID, err)
+	}
+}
+
+// List возвращает все токены всех провайдеров со статусом — для админки
+func List(ctx context.Context) ([]models.GitHubToken, error) {
+	rows, err := db.Pool.Query(ctx, `
+        SELECT id, provider, token, enabled, fail_count, last_used_at, rate_limit, rate_remaining,
+               rate_reset_at, cooldown_until, last_status, last_error, checked_at
+          FROM github_tokens
+         ORDER BY provider, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.GitHubToken
+	for rows.Next() {
+		var t models.GitHubToken
+		if err := rows.Scan(&t.ID, &t.Provider, &t.Token, &t.Enabled, &t.FailCount, &t.LastUsedAt, &t.RateLimit,
+			&t.RateRemaining, &t.RateResetAt, &t.CooldownUntil, &t.LastStatus, &t.LastError, &t.CheckedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// Add добавляет токен в пул (дубликаты игнорируются)
+func (p *Pool) Add(ctx context.Context, token string) error {
+	_, err := db.Pool.Exec(ctx,
+		`INSERT INTO github_tokens(token, provider) VALUES($1,$2) ON CONFLICT(token) DO NOTHING`,
+		token, p.provider)
+	return err
+}
+
+// SetEnabled включает или выключает токен; включение сбрасывает счётчик ошибок
+func SetEnabled(ctx context.Context, id int, enabled bool) error {
+	_, err := db.Pool.Exec(ctx, `
+        UPDATE github_tokens
+           SET enabled = $2,
+               fail_count = CASE WHEN $2 THEN 0 ELSE fail_count END,
+               cooldown_until = CASE WHEN $2 THEN NULL ELSE cooldown_until END
+         WHERE id = $1`, id, enabled)
+	return err
+}
+
+// Delete удаляет токен из пула
+func Delete(ctx context.Context, id int) error {
+	_, err := db.Pool.Exec(ctx, `DELETE FROM github_tokens WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,53 @@
+package uploads
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// References возвращает адреса картинок, на которые ещё ссылается сайт
+type References func(ctx context.Context) ([]string, error)
+
+// GC удаляет файлы Save, на которые никто не ссылается: заменённые
+// и удалённые картинки вместе с их уменьшенными копиями. Файлы моложе
+// grace не трогаем — их могли только что загрузить, но ещё не сохранить
+// ссылку в БД. Старые загрузки (не по хэшу) не удаляются.
+func (s *Store) GC(ctx context.Context, refs References, grace time.Duration) (int, error) {
+	urls, err := refs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	base := s.Backend.URL("")
+	used := map[string]bool{}
+	for _, u := range urls {
+		name, ok := strings.CutPrefix(u, base)
+		if !ok {
+			continue
+		}
+		if m := nameRe.FindStringSubmatch(name); m != nil {
+			used[m[1]] = true
+		}
+	}
+
+	objs, err := s.Backend.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	cutoff := time.Now().Add(-grace)
+	for _, o := range objs {
+		if ctx.Err() != nil {
+			return removed, ctx.Err()
+		}
+		m := nameRe.FindStringSubmatch(o.Key)
+		if m == nil || used[m[1]] || o.ModTime.After(cutoff) {
+			continue
+		}
+		if err := s.Backend.Delete(ctx, o.Key); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,64 @@
+package uploads
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"Site/storage"
+)
+
+const (
+	hashKept     = "0123456789abcdef0123456789abcdef"
+	hashOrphan   = "fedcba9876543210fedcba9876543210"
+	hashUploaded = "00112233445566778899aabbccddeeff"
+)
+
+// TestGC: удаляются только старые файлы Save без ссылок (вместе с
+// уменьшенными копиями); файлы со ссылкой, свежие и старые загрузки
+// не по хэшу остаются
+func TestGC(t *testing.T) {
+	dir := t.TempDir()
+	s := New(storage.NewLocal(dir, URLPrefix))
+	old := time.Now().Add(-48 * time.Hour)
+	files := map[string]time.Time{
+		hashKept + ".png":       old,
+		hashKept + "_320.png":   old,
+		hashOrphan + ".jpg":     old,
+		hashOrphan + "_640.jpg": old,
+		// только что загружен, ссылка ещё не сохранена
+		hashUploaded + ".png": time.Now(),
+		// загрузка до хэшированных имён
+		"photo.png": old,
+	}
+	for name, mtime := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refs := func(ctx context.Context) ([]string, error) {
+		return []string{URLPrefix + hashKept + ".png", "https://example.com/uploads/" + hashOrphan + ".jpg"}, nil
+	}
+	n, err := s.GC(context.Background(), refs, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("removed %d file(s), want 2", n)
+	}
+	for name := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		gone := os.IsNotExist(err)
+		if want := strings.HasPrefix(name, hashOrphan); gone != want {
+			t.Errorf("%s: removed = %v, want %v", name, gone, want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Package uploads — приём загруженных картинок.
+//
+// Файл проверяется по сигнатуре (а не по расширению и Content-Type
+// клиента), декодируется и кодируется заново: так из него пропадают
+// EXIF (с геометкой) и любые «прицепы» после данных картинки. Имя файла —
+// хэш содержимого, поэтому повторная загрузка той же картинки не плодит
+// копий, а имя клиента никуда не попадает. Для карточек рядом кладутся
+// уменьшенные копии <hash>_<ширина>.<ext> (см. ThumbWidths).
+package uploads
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"Site/storage"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+var (
+	// ErrTooLarge — файл больше Store.MaxBytes или картинка больше MaxPixels
+	ErrTooLarge = errors.New("uploads: файл слишком большой")
+	// ErrUnsupported — не JPEG/PNG/GIF/WebP или файл повреждён
+	ErrUnsupported = errors.New("uploads: поддерживаются только JPEG, PNG, GIF и WebP")
+)
+
+// ThumbWidths — ширины уменьшенных копий для srcset карточек
+var ThumbWidths = []int{320, 640, 1280}
+
+// jpegQuality — качество при перекодировании фотографий
+const jpegQuality = 85
+
+// Store — приём картинок поверх хранилища файлов
+type Store struct {
+	Backend storage.Backend
+	// MaxBytes — предел размера одного файла
+	MaxBytes int64
+	// MaxPixels — предел ширина×высота, защита от «бомб» вроде 50000×50000 PNG
+	MaxPixels int
+}
+
+// Каталог локального хранилища и адрес, под которым сайт раздаёт загрузки
+const (
+	LocalDir  = "static/uploads"
+	URLPrefix = "/uploads/"
+)
+
+// Default — загрузки сайта; до Init лежат на диске в LocalDir
+var Default = New(storage.NewLocal(LocalDir, URLPrefix))
+
+// New создаёт приёмник с пределами по умолчанию (10 МБ на файл)
+func New(b storage.Backend) *Store {
+	return &Store{
+		Backend:   b,
+		MaxBytes:  10 << 20,
+		MaxPixels: 40_000_000,
+	}
+}
+
+// Init настраивает Default из окружения: хранилище (см. storage.FromEnv) и
+//
+//	UPLOAD_MAX_MB -> предел размера одного файла в мегабайтах
+func Init() error {
+	b, err := storage.FromEnv(LocalDir, URLPrefix)
+	if err != nil {
+		return err
+	}
+	Default.Backend = b
+	if v, err := strconv.Atoi(strings.TrimSpace(os.Getenv("UPLOAD_MAX_MB"))); err == nil && v > 0 {
+		Default.MaxBytes = int64(v) << 20
+	}
+	return nil
+}
+
+// Image — сохранённая картинка
+type Image struct {
+	URL    string
+	Width  int
+	Height int
+	// Size — размер перекодированного файла в байтах (без уменьшенных копий)
+	Size int64
+}
+
+// FromRequest сохраняет картинку из поля формы; nil без ошибки — файл не прислали
+func (s *Store) FromRequest(r *http.Request, field string) (*Image, error) {
+	file, _, err := r.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return s.Save(r.Context(), file)
+}
+
+// sniff определяет формат по первым байтам
+func sniff(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg"
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return "png"
+	case bytes.HasPrefix(head, []byte("GIF87a")), bytes.HasPrefix(head, []byte("GIF89a")):
+		return "gif"
+	case len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WEBP")):
+		return "webp"
+	}
+	return ""
+}
+
+func decodeConfig(format string, r io.Reader) (image.Config, error) {
+	switch format {
+	case "jpeg":
+		return jpeg.DecodeConfig(r)
+	case "png":
+		return png.DecodeConfig(r)
+	case "gif":
+		return gif.DecodeConfig(r)
+	case "webp":
+		return webp.DecodeConfig(r)
+	}
+	return image.Config{}, ErrUnsupported
+}
+
+func decode(format string, r io.Reader) (image.Image, error) {
+	switch format {
+	case "jpeg":
+		return jpeg.Decode(r)
+	case "png":
+		return png.Decode(r)
+	case "gif":
+		// анимация не сохраняется — берётся первый кадр
+		return gif.Decode(r)
+	case "webp":
+		return webp.Decode(r)
+	}
+	return nil, ErrUnsupported
+}
+
+// Save проверяет, перекодирует и сохраняет картинку вместе с уменьшенными копиями
+func (s *Store) Save(ctx context.Context, r io.Reader) (*Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.MaxBytes {
+		return nil, ErrTooLarge
+	}
+	format := sniff(data)
+	if format == "" {
+		return nil, ErrUnsupported
+	}
+	// Размеры проверяем до декодирования, чтобы не выделять гигабайты
+	cfg, err := decodeConfig(format, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > s.MaxPixels {
+		return nil, ErrTooLarge
+	}
+	img, err := decode(format, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if format == "jpeg" {
+		// EXIF выбрасываем, но поворот камеры применяем к пикселям
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	// Фотографии остаются JPEG, остальное (прозрачность, палитра) — PNG
+	ext := "png"
+	if format == "jpeg" {
+		ext = "jpg"
+	}
+	full, err := encode(img, ext)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(full)
+	hash := hex.EncodeToString(sum[:16])
+
+	name := hash + "." + ext
+	if err := s.put(ctx, name, full); err != nil {
+		return nil, err
+	}
+	for _, w := range ThumbWidths {
+		thumb, err := encode(resize(img, w), ext)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.put(ctx, fmt.Sprintf("%s_%d.%s", hash, w, ext), thumb); err != nil {
+			return nil, err
+		}
+	}
+	b := img.Bounds()
+	return &Image{URL: s.Backend.URL(name), Width: b.Dx(), Height: b.Dy(), Size: int64(len(full))}, nil
+}
+
+func encode(img image.Image, ext string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if ext == "jpg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	return buf.Bytes(), err
+}
+
+// resize уменьшает картинку до ширины w с сохранением пропорций;
+// узкие картинки не растягиваются
+func resize(img image.Image, w int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= w {
+		return img
+	}
+	h := b.Dy() * w / b.Dx()
+	if h < 1 {
+		h = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// put кладёт файл в хранилище. Одинаковое содержимое даёт одинаковое имя,
+// так что перезапись ничего не меняет, а лишь освежает время для GC.
+func (s *Store) put(ctx context.Context, name string, data []byte) error {
+	ct := "image/png"
+	if strings.HasSuffix(name, ".jpg") {
+		ct = "image/jpeg"
+	}
+	return s.Backend.Put(ctx, name, bytes.NewReader(data), int64(len(data)), ct)
+}
+
+// nameRe — имена файлов, созданных Save: хэш, необязательная ширина, расширение
+var nameRe = regexp.MustCompile(`^([0-9a-f]{32})(?:_(\d+))?\.(jpg|png)$`)
+
+// ThumbURL — адрес уменьшенной копии шириной width для картинки из Save;
+// для внешних адресов и старых загрузок возвращает исходный адрес
+func ThumbURL(u string, width int) string {
+	dir, name := u[:strings.LastIndexByte(u, '/')+1], u[strings.LastIndexByte(u, '/')+1:]
+	m := nameRe.FindStringSubmatch(name)
+	if m == nil || m[2] != "" || dir != Default.Backend.URL("") {
+		return u
+	}
+	return fmt.Sprintf("%s%s_%d.%s", dir, m[1], width, m[3])
+}
+
+// Delete удаляет картинку из Save вместе с уменьшенными копиями;
+// чужие адреса и старые загрузки не трогает
+func (s *Store) Delete(ctx context.Context, u string) error {
+	name, ok := strings.CutPrefix(u, s.Backend.URL(""))
+	m := nameRe.FindStringSubmatch(name)
+	if !ok || m == nil || m[2] != "" {
+		return nil
+	}
+	if err := s.Backend.Delete(ctx, name); err != nil {
+		return err
+	}
+	for _, w := range ThumbWidths {
+		if err := s.Backend.Delete(ctx, fmt.Sprintf("%s_%d.%s", m[1], w, m[3])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Srcset — значение srcset со всеми уменьшенными копиями; "" для
+// картинок, у которых копий нет
+func Srcset(u string) string {
+	if ThumbURL(u, ThumbWidths[0]) == u {
+		return ""
+	}
+	parts := make([]string, 0, len(ThumbWidths))
+	for _, w := range ThumbWidths {
+		parts = append(parts, fmt.Sprintf("%s %dw", ThumbURL(u, w), w))
+	}
+	return strings.Join(parts, ", ")
+}